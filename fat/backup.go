@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/jrpc"
+	"github.com/azyablov/fat/lib/jrpc/show"
+)
+
+// Function saves target configuration extracted via JSON-RPC into dir, file is named as srlce does: hostname_swVersion.cfg.
+// Returns the name of the file.
+func backupConfig(t *lib.SRLTarget, dir string) (string, error) {
+	shVer, err := show.GetVersion(t)
+	if err != nil {
+		return "", fmt.Errorf("can't get version: %w", err)
+	}
+	cfg, err := jrpc.ExecCliText(t, "info")
+	if err != nil {
+		return "", fmt.Errorf("can't get configuration: %w", err)
+	}
+	if len(strings.TrimSpace(cfg)) == 0 {
+		return "", fmt.Errorf("empty configuration received")
+	}
+
+	cfgFileName := filepath.Join(dir, strings.Join([]string{shVer.Hostname, shVer.SoftwareVersion}, "_")+".cfg")
+	err = os.WriteFile(cfgFileName, []byte(cfg), 0640)
+	if err != nil {
+		return "", fmt.Errorf("can't save config file: %s", err)
+	}
+	return cfgFileName, nil
+}
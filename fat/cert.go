@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/gnoi/cert"
+	"github.com/azyablov/fat/lib/session"
+	log "github.com/sirupsen/logrus"
+)
+
+const certUsage = `Usage: fat cert <install|rotate|list|revoke> [flags]
+
+Certificate is signed by local CA: -rootCA certificate and -caKey private key.
+Rotated certificate is verified by new TLS session validated against -rootCA before rotation is finalized.
+
+Flags:
+`
+
+// Function runs cert command: fat cert <install|rotate|list|revoke> [flags].
+func runCert(args []string) error {
+	fs, t, o := newFlagSet("cert")
+	caKey := fs.String("caKey", "", "CA private key file in PEM format, CA certificate is taken from -rootCA")
+	certID := fs.String("certID", "clab-profile", "Certificate ID, SR Linux uses TLS server profile name")
+	cn := fs.String("cn", "", "Certificate common name, target hostname by default")
+	dnsNames := fs.String("dns", "", "Comma separated DNS SANs in addition to common name")
+	ips := fs.String("ip", "", "Comma separated IP SANs, target address is added, if target is specified by IP")
+	keySize := fs.Int("keySize", cert.DefaultKeySize, "RSA key size")
+	validity := fs.Duration("validity", cert.DefaultValidity, "Certificate validity")
+	localKey := fs.Bool("localKey", false, "Generate key pair locally instead of CSR on the target")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), certUsage)
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 {
+		fs.Usage()
+		return fmt.Errorf("cert action is mandatory, but missed")
+	}
+	action := args[0]
+	fs.Parse(args[1:])
+
+	closeLog, err := o.setup(t)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	contextLogger := log.WithFields(log.Fields{
+		"topic": "cert " + action,
+	})
+
+	switch action {
+	case "list":
+		certs, err := cert.GetCertificates(t)
+		if err != nil {
+			return err
+		}
+		printCerts(certs)
+		return nil
+	case "revoke":
+		if fs.NArg() == 0 {
+			return fmt.Errorf("certificate IDs to revoke are mandatory, but missed")
+		}
+		revoked, err := cert.RevokeCertificates(t, fs.Args())
+		for _, id := range revoked {
+			fmt.Printf("revoked: %s\n", id)
+		}
+		return err
+	case "install", "rotate":
+	default:
+		return fmt.Errorf("unknown cert action: %s", action)
+	}
+
+	if len(*t.RootCA) == 0 || len(*caKey) == 0 {
+		return fmt.Errorf("CA certificate (-rootCA) and private key (-caKey) are mandatory, but missed")
+	}
+	ca, err := cert.LoadCA(*t.RootCA, *caKey)
+	if err != nil {
+		return err
+	}
+	opts := certOptions(*t.Hostname, *certID, *cn, *dnsNames, *ips)
+	opts.KeySize = *keySize
+	opts.Validity = *validity
+	opts.LocalKey = *localKey
+	contextLogger.Debugf("certificate options: %+v", opts)
+
+	if action == "install" {
+		return cert.Install(t, ca, opts)
+	}
+	return cert.Rotate(t, ca, opts, func() error {
+		contextLogger.Debug("verifying new certificate")
+		return verifyCert(t)
+	})
+}
+
+// Function builds certificate options, target hostname is used as common name and SAN by default.
+func certOptions(hostname string, certID string, cn string, dnsNames string, ips string) *cert.Options {
+	host := hostname
+	if h, _, err := net.SplitHostPort(hostname); err == nil {
+		host = h
+	}
+	opts := &cert.Options{CertificateID: certID, CommonName: cn}
+	if len(opts.CommonName) == 0 {
+		opts.CommonName = host
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		opts.IPAddresses = append(opts.IPAddresses, ip)
+	} else {
+		opts.DNSNames = append(opts.DNSNames, host)
+	}
+	opts.DNSNames = append(opts.DNSNames, splitList(dnsNames)...)
+	for _, s := range splitList(ips) {
+		if ip := net.ParseIP(s); ip != nil {
+			opts.IPAddresses = append(opts.IPAddresses, ip)
+		}
+	}
+	return opts
+}
+
+// Function establishes new session validating target certificate against root CA.
+func verifyCert(t *lib.SRLTarget) error {
+	vt := *t
+	insecConn, skipVerify := false, false
+	vt.InsecConn = &insecConn
+	vt.SkipVerify = &skipVerify
+	s, err := session.New(&vt)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	_, err = cert.NewClient(s).GetCertificates()
+	return err
+}
+
+func printCerts(certs []cert.CertInfo) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSUBJECT\tISSUER\tNOT AFTER\tMODIFIED")
+	for _, c := range certs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.ID, c.Certificate.Subject, c.Certificate.Issuer,
+			c.Certificate.NotAfter.Format(time.RFC3339), c.ModificationTime.Format(time.RFC3339))
+	}
+	w.Flush()
+}
+
+// Function splits comma separated list skipping empty elements.
+func splitList(s string) []string {
+	var l []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); len(e) != 0 {
+			l = append(l, e)
+		}
+	}
+	return l
+}
@@ -0,0 +1,115 @@
+// fat is the fabric automation tool combining gNOI, gNMI and JSON-RPC workflows for SR Linux targets under subcommands.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	log "github.com/sirupsen/logrus"
+)
+
+// command is the subcommand of fat.
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []*command{
+	{name: "cert", usage: "Install, rotate, list or revoke TLS certificates of the target via gNOI", run: runCert},
+	{name: "health", usage: "Collect health events and artifacts of the component via gNOI together with config backup", run: runHealth},
+	{name: "telemetry", usage: "Stream configurable paths of the targets via gNMI Subscribe into stdout, file or Prometheus endpoint", run: runTelemetry},
+	{name: "upgrade", usage: "Back up config, install and activate software image via gNOI and verify the version after reboot", run: runUpgrade},
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name != flag.Arg(0) {
+			continue
+		}
+		err := c.run(flag.Args()[1:])
+		if err != nil {
+			log.WithFields(log.Fields{
+				"exec": c.name,
+			}).Fatal(err)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "unknown command: %s\n", flag.Arg(0))
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of %s:\n  %s <command> [arguments]\n\nCommands:\n", os.Args[0], os.Args[0])
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nUse \"%s <command> -h\" for more information about a command.\n", os.Args[0])
+}
+
+// commonOpt holds flags shared by all commands.
+type commonOpt struct {
+	retries      *int
+	retryBackoff *time.Duration
+	logFile      *string
+	d            *bool
+}
+
+// Function creates flag set of the command with target and common flags, the same ones srlce accepts.
+func newFlagSet(name string) (*flag.FlagSet, *lib.SRLTarget, *commonOpt) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	o := new(commonOpt)
+	o.retries = fs.Int("retries", 1, "Number of attempts for idempotent gNOI calls")
+	o.retryBackoff = fs.Duration("retryBackoff", time.Second, "Initial backoff between retries, grows exponentially with jitter")
+	o.logFile = fs.String("logFile", "", "Log all messages into specified log file instead of stderr")
+	o.d = fs.Bool("d", false, "Enable debug, by default warn")
+
+	t := new(lib.SRLTarget)
+	t.Username = fs.String("username", "admin", "Username")
+	t.Password = fs.String("password", "NokiaSrl1!", "Password")
+	t.Hostname = fs.String("target", "", "Target hostname")
+	t.PortgNOI = fs.Int("gNOIport", 57400, "gNOI port")
+	t.Timeout = fs.Duration("timeout", 10*time.Second, "Connection timeout")
+	t.InsecConn = fs.Bool("InsecConn", false, "TLS insecure connectivity")
+	t.SkipVerify = fs.Bool("SkipVerify", false, "skip TLS certificate chain verification")
+	t.RootCA = fs.String("rootCA", "", "CA certificate file in PEM format")
+	t.Cert = fs.String("cert", "", "Client certificate file in PEM format")
+	t.Key = fs.String("key", "", "Client private key file")
+	return fs, t, o
+}
+
+// Function checks mandatory target params, attaches retry policy and sets up logging.
+// Returned function closes log file, if any.
+func (o *commonOpt) setup(t *lib.SRLTarget) (func(), error) {
+	if len(*t.Hostname) == 0 {
+		return nil, fmt.Errorf("hostname is mandatory, but missed")
+	}
+	t.Retry = lib.NewRetryPolicy(*o.retries, *o.retryBackoff)
+
+	log.SetReportCaller(true)
+	log.SetLevel(log.WarnLevel)
+	if *o.d {
+		log.SetLevel(log.DebugLevel)
+	}
+	if *o.logFile == "" {
+		log.SetOutput(os.Stderr)
+		return func() {}, nil
+	}
+	fh, err := os.OpenFile(*o.logFile, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0740)
+	if err != nil {
+		return nil, fmt.Errorf("can't create/open log file: %s", err)
+	}
+	log.SetOutput(fh)
+	return func() { fh.Close() }, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/azyablov/fat/lib/gnoi/healthz"
+	"github.com/azyablov/fat/lib/session"
+	log "github.com/sirupsen/logrus"
+)
+
+// Function runs health command: collects health events of the component with their artifacts and config backup into single directory.
+func runHealth(args []string) error {
+	fs, t, o := newFlagSet("health")
+	t.PortJRpc = fs.Int("JRPCport", 443, "JSON RPC port used for config backup")
+	component := fs.String("component", "", "Component to collect health diagnostics for, e.g. Linecard1")
+	includeAck := fs.Bool("includeAck", false, "Include acknowledged health events")
+	noArtifacts := fs.Bool("noArtifacts", false, "Skip artifacts download")
+	noBackup := fs.Bool("noBackup", false, "Skip config backup via JSON RPC")
+	outDir := fs.String("outDir", ".", "Directory to create diagnostics directory in")
+	fs.Parse(args)
+
+	closeLog, err := o.setup(t)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	if len(*component) == 0 {
+		return fmt.Errorf("component is mandatory, but missed")
+	}
+	contextLogger := log.WithFields(log.Fields{
+		"topic": "health",
+	})
+
+	dir := filepath.Join(*outDir, fmt.Sprintf("%s_%s_%s", *t.Hostname, strings.ReplaceAll(*component, " ", "_"), time.Now().Format("20060102-150405")))
+	err = os.MkdirAll(dir, 0750)
+	if err != nil {
+		return fmt.Errorf("can't create directory: %s", err)
+	}
+
+	s, err := session.New(t)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	hc := healthz.NewClient(s)
+
+	css, err := hc.List(*component, *includeAck)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(css, "", "  ")
+	if err != nil {
+		return fmt.Errorf("can't marshal health status: %s", err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "health.json"), b, 0640)
+	if err != nil {
+		return fmt.Errorf("can't save health status: %s", err)
+	}
+	fmt.Printf("%d health events of %s collected\n", len(css), *component)
+
+	// Artifact download failure doesn't stop collection of the rest diagnostics.
+	var failed int
+	if !*noArtifacts {
+		for _, a := range artifacts(css) {
+			lFile, err := hc.SaveArtifact(a.ID, dir)
+			if err != nil {
+				failed++
+				contextLogger.Errorf("can't save artifact %s: %s", a.ID, err)
+				continue
+			}
+			contextLogger.Infof("artifact %s saved into %s", a.ID, lFile)
+		}
+	}
+
+	if !*noBackup {
+		cfgFileName, err := backupConfig(t, dir)
+		if err != nil {
+			return fmt.Errorf("config backup failed: %w", err)
+		}
+		contextLogger.Infof("config saved into %s", cfgFileName)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d artifacts weren't saved, diagnostics in %s are incomplete", failed, dir)
+	}
+	fmt.Printf("diagnostics saved into %s\n", dir)
+	return nil
+}
+
+// Function returns artifacts of all events and their subcomponents, every artifact once.
+func artifacts(css []*healthz.ComponentStatus) []*healthz.Artifact {
+	seen := make(map[string]bool)
+	var as []*healthz.Artifact
+	var walk func(css []*healthz.ComponentStatus)
+	walk = func(css []*healthz.ComponentStatus) {
+		for _, cs := range css {
+			for _, a := range cs.Artifacts {
+				if seen[a.ID] {
+					continue
+				}
+				seen[a.ID] = true
+				as = append(as, a)
+			}
+			walk(cs.Subcomponents)
+		}
+	}
+	walk(css)
+	return as
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/gnmi"
+	"github.com/azyablov/fat/lib/session"
+	log "github.com/sirupsen/logrus"
+)
+
+// Paths sampled by default: interface counters and BGP neighbor state.
+const defaultTelemetryPaths = "/interface[name=*]/statistics,/network-instance[name=*]/protocols/bgp/neighbor[peer-address=*]/session-state"
+
+// Prefix of metric names exposed to Prometheus.
+const metricPrefix = "srl"
+
+// sample is the value of the leaf received from the target.
+type sample struct {
+	Time   time.Time `json:"time"`
+	Target string    `json:"target"`
+	Path   string    `json:"path"`
+	Value  any       `json:"value,omitempty"`
+	Delete bool      `json:"delete,omitempty"`
+}
+
+// sink is the output samples of all targets are written into, calls are serialized by the caller.
+type sink interface {
+	write(s *sample) error
+	close() error
+}
+
+// Function runs telemetry command: subscribes to the paths of all targets and writes received samples into the output till interrupted.
+func runTelemetry(args []string) error {
+	fs, t, o := newFlagSet("telemetry")
+	t.PortgNMI = fs.Int("gNMIport", 57400, "gNMI port")
+	paths := fs.String("paths", defaultTelemetryPaths, "Comma separated list of paths to subscribe to")
+	mode := fs.String("mode", gnmi.ModeSample, "Subscription mode: sample, on_change or target_defined")
+	interval := fs.Duration("interval", 10*time.Second, "Sample interval, used in sample mode")
+	duration := fs.Duration("duration", 0, "Stop after specified duration, runs till interrupted by default")
+	output := fs.String("output", "stdout", "Output: stdout or file (JSON lines), prometheus (exposition endpoint)")
+	outFile := fs.String("file", "telemetry.json", "File to append samples into, used with -output file")
+	listen := fs.String("listen", ":9273", "Address to serve /metrics on, used with -output prometheus")
+	fs.Parse(args)
+
+	closeLog, err := o.setup(t)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	var subs []*gnmi.Subscription
+	for _, p := range strings.Split(*paths, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			subs = append(subs, &gnmi.Subscription{Path: p, Mode: *mode, SampleInterval: *interval})
+		}
+	}
+	// Incorrect paths and mode are reported at once rather than retried by every target.
+	err = gnmi.CheckSubscriptions(subs)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	var out sink
+	switch *output {
+	case "stdout":
+		out = &jsonSink{w: os.Stdout}
+	case "file":
+		fh, err := os.OpenFile(*outFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+		if err != nil {
+			return fmt.Errorf("can't open output file: %s", err)
+		}
+		out = &jsonSink{w: fh, c: fh}
+	case "prometheus":
+		ps, err := newPromSink(*listen)
+		if err != nil {
+			return err
+		}
+		out = ps
+	default:
+		return fmt.Errorf("unknown output %s", *output)
+	}
+	defer out.close()
+
+	// Every target is subscribed over its own session, samples are written one by one.
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, h := range strings.Split(*t.Hostname, ",") {
+		tt := targetCopy(t, strings.TrimSpace(h))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := collect(ctx, tt, subs, func(s *sample) error {
+				mu.Lock()
+				defer mu.Unlock()
+				return out.write(s)
+			})
+			if err != nil {
+				log.WithFields(log.Fields{
+					"target": *tt.Hostname,
+				}).Errorf("telemetry stopped: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// Function returns copy of the target with another hostname, the rest of attributes are shared.
+func targetCopy(t *lib.SRLTarget, hostname string) *lib.SRLTarget {
+	tt := *t
+	tt.Hostname = &hostname
+	return &tt
+}
+
+// Function subscribes to the paths of the target and passes received leaves to fn.
+// Subscription is re-established with backoff on errors, returns when ctx is done or fn fails.
+func collect(ctx context.Context, t *lib.SRLTarget, subs []*gnmi.Subscription, fn func(s *sample) error) error {
+	contextLogger := log.WithFields(log.Fields{
+		"target": *t.Hostname,
+	})
+	s, err := session.NewWithPort(t, *t.PortgNMI)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	err = gnmi.NewClient(s).SubscribeRetry(ctx, subs, func(gn *gnmi.Notification) error {
+		if gn.Sync {
+			contextLogger.Debug("initial state received")
+			return nil
+		}
+		for _, u := range gn.Updates {
+			for _, l := range gnmi.Leaves(u) {
+				err := fn(&sample{Time: gn.Timestamp, Target: *t.Hostname, Path: l.Path, Value: l.Value})
+				if err != nil {
+					return err
+				}
+			}
+		}
+		for _, p := range gn.Deletes {
+			err := fn(&sample{Time: gn.Timestamp, Target: *t.Hostname, Path: p, Delete: true})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, func(err error, d time.Duration) {
+		contextLogger.Warnf("subscription is broken: %s; reconnecting in %s", err, d)
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// jsonSink writes samples as JSON lines.
+type jsonSink struct {
+	w io.Writer
+	c io.Closer // closed by close(), if set
+}
+
+func (s *jsonSink) write(smp *sample) error {
+	b, err := json.Marshal(smp)
+	if err != nil {
+		return fmt.Errorf("can't marshal sample: %s", err)
+	}
+	_, err = s.w.Write(append(b, '\n'))
+	if err != nil {
+		return fmt.Errorf("can't write sample: %s", err)
+	}
+	return nil
+}
+
+func (s *jsonSink) close() error {
+	if s.c == nil {
+		return nil
+	}
+	return s.c.Close()
+}
+
+// promSink keeps the last value of every leaf and serves them in Prometheus text exposition format.
+// Numeric and boolean leaves are exposed as their values, string ones as value label of the metric set to 1.
+type promSink struct {
+	mu     sync.Mutex
+	series map[string]*series // series id to the last value
+	srv    *http.Server
+}
+
+// series is the metric with labels and value.
+type series struct {
+	name   string
+	labels []string // name="value" pairs, sorted
+	value  float64
+}
+
+var nonMetricChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// Function starts HTTP server exposing /metrics.
+func newPromSink(addr string) (*promSink, error) {
+	s := &promSink{series: make(map[string]*series)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.serveHTTP)
+	s.srv = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("can't serve metrics on %s: %s", addr, err)
+	}
+	go s.srv.Serve(ln)
+	log.Infof("serving metrics on %s/metrics", addr)
+	return s, nil
+}
+
+func (s *promSink) write(smp *sample) error {
+	name, labels, err := metric(smp.Path)
+	if err != nil {
+		return err
+	}
+	labels = append(labels, label("target", smp.Target))
+	id := name + "{" + strings.Join(labels, ",")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if smp.Delete {
+		// Whole subtree could be deleted, e.g. BGP neighbor.
+		for k, sr := range s.series {
+			if (sr.name == name || strings.HasPrefix(sr.name, name+"_")) && containsAll(sr.labels, labels) {
+				delete(s.series, k)
+			}
+		}
+		return nil
+	}
+
+	v, str := metricValue(smp.Value)
+	if str != "" {
+		labels = append(labels, label("value", str))
+	}
+	sort.Strings(labels)
+	s.series[id] = &series{name: name, labels: labels, value: v}
+	return nil
+}
+
+func (s *promSink) close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.srv.Shutdown(ctx)
+}
+
+func (s *promSink) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	byName := make(map[string][]*series)
+	for _, sr := range s.series {
+		byName[sr.name] = append(byName[sr.name], sr)
+	}
+	s.mu.Unlock()
+
+	names := make([]string, 0, len(byName))
+	for n := range byName {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	for _, n := range names {
+		ss := byName[n]
+		sort.Slice(ss, func(i, j int) bool {
+			return strings.Join(ss[i].labels, ",") < strings.Join(ss[j].labels, ",")
+		})
+		fmt.Fprintf(w, "# TYPE %s untyped\n", n)
+		for _, sr := range ss {
+			fmt.Fprintf(w, "%s{%s} %s\n", n, strings.Join(sr.labels, ","), strconv.FormatFloat(sr.value, 'g', -1, 64))
+		}
+	}
+}
+
+// Function returns metric name and labels of the path, e.g. srl_interface_statistics_in_octets
+// and interface_name="ethernet-1/1" for /interface[name=ethernet-1/1]/statistics/in-octets.
+func metric(p string) (string, []string, error) {
+	gp, err := gnmi.ParsePath(p)
+	if err != nil {
+		return "", nil, err
+	}
+	parts := []string{metricPrefix}
+	var labels []string
+	for _, e := range gp.GetElem() {
+		parts = append(parts, e.GetName())
+		for k, v := range e.GetKey() {
+			labels = append(labels, label(e.GetName()+"_"+k, v))
+		}
+	}
+	sort.Strings(labels)
+	return nonMetricChars.ReplaceAllString(strings.Join(parts, "_"), "_"), labels, nil
+}
+
+// Escapes label value as Prometheus text format does: only backslash, double quote and line feed.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Function returns label pair with escaped value.
+func label(name string, value string) string {
+	return nonMetricChars.ReplaceAllString(name, "_") + `="` + labelEscaper.Replace(value) + `"`
+}
+
+// Function returns numeric value of the leaf, or 1 and the string value for non-numeric leaves.
+func metricValue(v any) (float64, string) {
+	switch tv := v.(type) {
+	case json.Number:
+		f, err := tv.Float64()
+		if err == nil {
+			return f, ""
+		}
+		return 1, tv.String()
+	case bool:
+		if tv {
+			return 1, ""
+		}
+		return 0, ""
+	case int64:
+		return float64(tv), ""
+	case uint64:
+		return float64(tv), ""
+	case float64:
+		return tv, ""
+	case string:
+		// Counters are encoded as strings in JSON_IETF.
+		f, err := strconv.ParseFloat(tv, 64)
+		if err == nil {
+			return f, ""
+		}
+		return 1, tv
+	default:
+		return 1, fmt.Sprint(tv)
+	}
+}
+
+// Function returns true, if all the labels are in ls.
+func containsAll(ls []string, labels []string) bool {
+	for _, l := range labels {
+		found := false
+		for _, sl := range ls {
+			if sl == l {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMetric(t *testing.T) {
+	testData := []struct {
+		testName  string
+		path      string
+		expName   string
+		expLabels []string
+		expErr    bool
+	}{
+		{
+			testName:  "Interface counter",
+			path:      "/interface[name=ethernet-1/1]/statistics/in-octets",
+			expName:   "srl_interface_statistics_in_octets",
+			expLabels: []string{`interface_name="ethernet-1/1"`},
+		},
+		{
+			testName: "BGP neighbor state",
+			path:     "/network-instance[name=default]/protocols/bgp/neighbor[peer-address=10.0.0.1]/session-state",
+			expName:  "srl_network_instance_protocols_bgp_neighbor_session_state",
+			expLabels: []string{
+				`neighbor_peer_address="10.0.0.1"`,
+				`network_instance_name="default"`,
+			},
+		},
+		{
+			testName: "No keys",
+			path:     "/system/name/host-name",
+			expName:  "srl_system_name_host_name",
+		},
+		{
+			testName: "Incorrect path",
+			path:     "/interface[name=ethernet-1/1/statistics",
+			expErr:   true,
+		},
+	}
+
+	for _, d := range testData {
+		t.Run(d.testName, func(t *testing.T) {
+			name, labels, err := metric(d.path)
+			if d.expErr {
+				if err == nil {
+					t.Errorf("expected an error for %s", d.path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got an error from metric(): %v", err)
+			}
+			if name != d.expName {
+				t.Errorf("expected name %s; got: %s", d.expName, name)
+			}
+			if !reflect.DeepEqual(labels, d.expLabels) {
+				t.Errorf("expected labels %v; got: %v", d.expLabels, labels)
+			}
+		})
+	}
+}
+
+func TestMetricValue(t *testing.T) {
+	testData := []struct {
+		testName string
+		value    any
+		expValue float64
+		expStr   string
+	}{
+		{testName: "Counter as string", value: "18446744073709551615", expValue: 18446744073709551615},
+		{testName: "JSON number", value: json.Number("1500"), expValue: 1500},
+		{testName: "Float", value: 0.5, expValue: 0.5},
+		{testName: "Uint", value: uint64(10), expValue: 10},
+		{testName: "True", value: true, expValue: 1},
+		{testName: "False", value: false, expValue: 0},
+		{testName: "Enum", value: "established", expValue: 1, expStr: "established"},
+		{testName: "Non numeric JSON number", value: json.Number("up"), expValue: 1, expStr: "up"},
+		{testName: "Leaf-list", value: []any{"a", "b"}, expValue: 1, expStr: "[a b]"},
+	}
+
+	for _, d := range testData {
+		t.Run(d.testName, func(t *testing.T) {
+			v, str := metricValue(d.value)
+			if v != d.expValue || str != d.expStr {
+				t.Errorf("expected %v, %q; got: %v, %q", d.expValue, d.expStr, v, str)
+			}
+		})
+	}
+}
+
+func TestLabel(t *testing.T) {
+	testData := []struct {
+		testName string
+		name     string
+		value    string
+		expLabel string
+	}{
+		{testName: "Plain", name: "target", value: "leaf1", expLabel: `target="leaf1"`},
+		{testName: "Name sanitized", name: "peer-address", value: "10.0.0.1", expLabel: `peer_address="10.0.0.1"`},
+		{testName: "Value escaped", name: "description", value: "to \"spine1\"\n", expLabel: `description="to \"spine1\"\n"`},
+		{testName: "Backslash escaped", name: "path", value: `c:\tmp`, expLabel: `path="c:\\tmp"`},
+		{testName: "Non-ASCII kept", name: "description", value: "uplink → spine1\t", expLabel: "description=\"uplink → spine1\t\""},
+	}
+
+	for _, d := range testData {
+		t.Run(d.testName, func(t *testing.T) {
+			if l := label(d.name, d.value); l != d.expLabel {
+				t.Errorf("expected %s; got: %s", d.expLabel, l)
+			}
+		})
+	}
+}
+
+func TestPromSink(t *testing.T) {
+	testData := []struct {
+		testName string
+		samples  []*sample
+		exp      string
+	}{
+		{
+			testName: "Numeric and string leaves",
+			samples: []*sample{
+				{Target: "leaf1", Path: "/interface[name=ethernet-1/2]/statistics/in-octets", Value: "20"},
+				{Target: "leaf1", Path: "/interface[name=ethernet-1/1]/statistics/in-octets", Value: "10"},
+				{Target: "leaf1", Path: "/interface[name=ethernet-1/1]/statistics/in-octets", Value: "11"},
+				{Target: "leaf1", Path: "/network-instance[name=default]/protocols/bgp/neighbor[peer-address=10.0.0.1]/session-state", Value: "established"},
+			},
+			exp: `# TYPE srl_interface_statistics_in_octets untyped
+srl_interface_statistics_in_octets{interface_name="ethernet-1/1",target="leaf1"} 11
+srl_interface_statistics_in_octets{interface_name="ethernet-1/2",target="leaf1"} 20
+# TYPE srl_network_instance_protocols_bgp_neighbor_session_state untyped
+srl_network_instance_protocols_bgp_neighbor_session_state{neighbor_peer_address="10.0.0.1",network_instance_name="default",target="leaf1",value="established"} 1
+`,
+		},
+		{
+			testName: "Deleted subtree",
+			samples: []*sample{
+				{Target: "leaf1", Path: "/network-instance[name=default]/protocols/bgp/neighbor[peer-address=10.0.0.1]/session-state", Value: "established"},
+				{Target: "leaf1", Path: "/network-instance[name=default]/protocols/bgp/neighbor[peer-address=10.0.0.2]/session-state", Value: "active"},
+				{Target: "leaf2", Path: "/network-instance[name=default]/protocols/bgp/neighbor[peer-address=10.0.0.1]/session-state", Value: "established"},
+				{Target: "leaf1", Path: "/network-instance[name=default]/protocols/bgp/neighbor[peer-address=10.0.0.1]", Delete: true},
+			},
+			exp: `# TYPE srl_network_instance_protocols_bgp_neighbor_session_state untyped
+srl_network_instance_protocols_bgp_neighbor_session_state{neighbor_peer_address="10.0.0.1",network_instance_name="default",target="leaf2",value="established"} 1
+srl_network_instance_protocols_bgp_neighbor_session_state{neighbor_peer_address="10.0.0.2",network_instance_name="default",target="leaf1",value="active"} 1
+`,
+		},
+		{
+			testName: "Deleted leaf w/o siblings sharing name prefix",
+			samples: []*sample{
+				{Target: "leaf1", Path: "/interface[name=ethernet-1/1]/statistics/in-error", Value: "1"},
+				{Target: "leaf1", Path: "/interface[name=ethernet-1/1]/statistics/in-errors", Value: "2"},
+				{Target: "leaf1", Path: "/interface[name=ethernet-1/1]/statistics/in-error", Delete: true},
+			},
+			exp: `# TYPE srl_interface_statistics_in_errors untyped
+srl_interface_statistics_in_errors{interface_name="ethernet-1/1",target="leaf1"} 2
+`,
+		},
+	}
+
+	for _, d := range testData {
+		t.Run(d.testName, func(t *testing.T) {
+			s := &promSink{series: make(map[string]*series)}
+			for _, smp := range d.samples {
+				if err := s.write(smp); err != nil {
+					t.Fatalf("got an error from write(): %v", err)
+				}
+			}
+			rec := httptest.NewRecorder()
+			s.serveHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+			if rec.Body.String() != d.exp {
+				t.Errorf("expected exposition:\n%s\ngot:\n%s", d.exp, rec.Body.String())
+			}
+		})
+	}
+}
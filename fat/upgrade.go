@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	gnoios "github.com/azyablov/fat/lib/gnoi/os"
+	"github.com/azyablov/fat/lib/session"
+	log "github.com/sirupsen/logrus"
+)
+
+// Function runs upgrade command: backs up config, installs and activates the image, waits for the reboot and verifies the version.
+func runUpgrade(args []string) error {
+	fs, t, o := newFlagSet("upgrade")
+	t.PortJRpc = fs.Int("JRPCport", 443, "JSON RPC port used for config backup")
+	image := fs.String("image", "", "SR Linux image file to install")
+	version := fs.String("version", "", "Version of the image, e.g. 23.3.1-343")
+	noBackup := fs.Bool("noBackup", false, "Skip config backup via JSON RPC")
+	backupDir := fs.String("backupDir", ".", "Directory to save config backup into")
+	validationTimeout := fs.Duration("validationTimeout", gnoios.DefaultValidationTimeout, "Time the target is given to validate the image")
+	waitInterval := fs.Duration("waitInterval", 15*time.Second, "Interval between version checks while the target reboots")
+	waitTimeout := fs.Duration("waitTimeout", 20*time.Minute, "Time to wait for the target to come up with the new version")
+	fs.Parse(args)
+
+	closeLog, err := o.setup(t)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	if len(*image) == 0 || len(*version) == 0 {
+		return fmt.Errorf("image and version are mandatory, but missed")
+	}
+	contextLogger := log.WithFields(log.Fields{
+		"topic": "upgrade",
+	})
+
+	s, err := session.New(t)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	oc := gnoios.NewClient(s)
+
+	cur, err := oc.Verify()
+	if err != nil {
+		return err
+	}
+	if cur.Version == *version {
+		fmt.Printf("%s runs %s already\n", *t.Hostname, *version)
+		return nil
+	}
+	contextLogger.Infof("upgrading %s from %s to %s", *t.Hostname, cur.Version, *version)
+
+	if !*noBackup {
+		cfgFileName, err := backupConfig(t, *backupDir)
+		if err != nil {
+			return fmt.Errorf("config backup failed: %w", err)
+		}
+		contextLogger.Infof("config saved into %s", cfgFileName)
+	}
+
+	f, err := os.Open(*image)
+	if err != nil {
+		return fmt.Errorf("can't open image: %s", err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("can't stat image: %s", err)
+	}
+	var reported int64 = -1
+	res, err := oc.InstallReader(f, &gnoios.InstallOptions{Version: *version, ValidationTimeout: *validationTimeout}, func(sent int64, received uint64) {
+		// Reporting every 10% only.
+		if p := sent * 10 / (fi.Size() + 1); p != reported {
+			reported = p
+			contextLogger.Debugf("sent %d of %d bytes, received by the target %d", sent, fi.Size(), received)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if res.Skipped {
+		contextLogger.Infof("image %s is present on the target already, transfer skipped", res.Version)
+	} else {
+		contextLogger.Infof("image %s installed: %s", res.Version, res.Description)
+	}
+
+	err = oc.Activate(*version, false)
+	if err != nil {
+		return err
+	}
+	contextLogger.Infof("%s activated, waiting for reboot", *version)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err = oc.WaitForVersion(ctx, *version, *waitInterval, *waitTimeout)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s upgraded to %s\n", *t.Hostname, *version)
+	return nil
+}
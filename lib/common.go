@@ -38,6 +38,7 @@ type SRLTarget struct {
 	Cred
 	SSHAttr
 	TLSAttr
+	Retry *RetryPolicy // Retry policy for idempotent JSON-RPC and gNOI calls, nil means no retries.
 }
 
 // Function returns the list of indexes substring within provided string or empty slice, if no substring found.
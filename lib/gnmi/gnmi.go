@@ -0,0 +1,247 @@
+// Package gnmi wraps gNMI RPCs to retrieve config and state of the target in JSON_IETF encoding.
+package gnmi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Data types to retrieve.
+const (
+	DataAll    = "all"
+	DataConfig = "config"
+	DataState  = "state"
+)
+
+const (
+	pathHostname = "/system/name/host-name"
+	pathVersion  = "/system/information/version"
+)
+
+var dataTypes = map[string]gpb.GetRequest_DataType{
+	DataAll:    gpb.GetRequest_ALL,
+	DataConfig: gpb.GetRequest_CONFIG,
+	DataState:  gpb.GetRequest_STATE,
+}
+
+// Update is the value of the path received from the target.
+type Update struct {
+	Path  string
+	Value any // Decoded JSON tree: map[string]any, []any, string, json.Number or bool; or scalar value.
+}
+
+// Version is the subset of `show version` available via gNMI.
+type Version struct {
+	Hostname        string
+	SoftwareVersion string // e.g. v23.3.1
+	BuildNumber     string // e.g. 343-gab924f2e64
+}
+
+// Client executes gNMI RPCs over the shared session.
+type Client struct {
+	t  *lib.SRLTarget
+	gc gpb.GNMIClient
+}
+
+// Creates gNMI client over provided session, session should be established to gNMI port.
+func NewClient(s *session.Session) *Client {
+	return &Client{t: s.Target(), gc: gpb.NewGNMIClient(s.Conn())}
+}
+
+// Returns values of the paths of data type (DataAll, DataConfig or DataState).
+func Get(t *lib.SRLTarget, dataType string, paths ...string) ([]*Update, error) {
+	var us []*Update
+	err := session.WithSession(t, *t.PortgNMI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		us, err = c.Get(dataType, paths...)
+		return err
+	})
+	return us, err
+}
+
+// Returns values of the paths of data type (DataAll, DataConfig or DataState).
+func (c *Client) Get(dataType string, paths ...string) ([]*Update, error) {
+	dt, ok := dataTypes[dataType]
+	if !ok {
+		return nil, fmt.Errorf("unknown data type %s", dataType)
+	}
+	req := &gpb.GetRequest{Type: dt, Encoding: gpb.Encoding_JSON_IETF}
+	for _, p := range paths {
+		gp, err := ParsePath(p)
+		if err != nil {
+			return nil, err
+		}
+		req.Path = append(req.Path, gp)
+	}
+
+	var gResp *gpb.GetResponse
+	err := c.t.Retry.Do(session.Retryable, func() error {
+		// Creating context with timeout.
+		ctx, cancel := context.WithTimeout(context.Background(), *c.t.Timeout)
+		defer cancel()
+
+		var err error
+		gResp, err = c.gc.Get(ctx, req)
+		if err != nil {
+			return fmt.Errorf("can't exec Get request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var us []*Update
+	for _, gn := range gResp.GetNotification() {
+		n, err := notification(gn)
+		if err != nil {
+			return nil, err
+		}
+		us = append(us, n.Updates...)
+	}
+	return us, nil
+}
+
+// Returns JSON tree of the path of data type (DataAll, DataConfig or DataState), e.g. the whole config for "/".
+func GetJSON(t *lib.SRLTarget, dataType string, path string) (map[string]any, error) {
+	var m map[string]any
+	err := session.WithSession(t, *t.PortgNMI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		m, err = c.GetJSON(dataType, path)
+		return err
+	})
+	return m, err
+}
+
+// Returns JSON tree of the path of data type (DataAll, DataConfig or DataState), e.g. the whole config for "/".
+// Leaf values are put into the tree under the name of the leaf.
+func (c *Client) GetJSON(dataType string, path string) (map[string]any, error) {
+	us, err := c.Get(dataType, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(us) == 0 {
+		return nil, fmt.Errorf("no data received for %s", path)
+	}
+	m := make(map[string]any)
+	for _, u := range us {
+		switch v := u.Value.(type) {
+		case map[string]any:
+			for k, cv := range v {
+				m[k] = cv
+			}
+		default:
+			m[u.Path[strings.LastIndex(u.Path, "/")+1:]] = v
+		}
+	}
+	return m, nil
+}
+
+// Returns hostname and software version of the target.
+func GetVersion(t *lib.SRLTarget) (*Version, error) {
+	var v *Version
+	err := session.WithSession(t, *t.PortgNMI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		v, err = c.GetVersion()
+		return err
+	})
+	return v, err
+}
+
+// Returns hostname and software version of the target.
+func (c *Client) GetVersion() (*Version, error) {
+	us, err := c.Get(DataState, pathHostname, pathVersion)
+	if err != nil {
+		return nil, err
+	}
+	v := new(Version)
+	for _, u := range us {
+		s, ok := leafString(u.Value)
+		if !ok {
+			return nil, fmt.Errorf("unexpected value of %s: %v", u.Path, u.Value)
+		}
+		switch {
+		case strings.HasSuffix(u.Path, pathHostname):
+			v.Hostname = s
+		case strings.HasSuffix(u.Path, pathVersion):
+			// Version is reported with build number, e.g. v23.3.1-343-gab924f2e64.
+			v.SoftwareVersion = s
+			if i := strings.Index(s, "-"); i != -1 {
+				v.SoftwareVersion, v.BuildNumber = s[:i], s[i+1:]
+			}
+		}
+	}
+	if v.Hostname == "" || v.SoftwareVersion == "" {
+		return nil, fmt.Errorf("hostname or version is missed in the response")
+	}
+	return v, nil
+}
+
+// Function returns leaf value as string, leaf could be wrapped into object by the target, e.g. {"host-name": "leaf1"}.
+func leafString(v any) (string, bool) {
+	if m, ok := v.(map[string]any); ok && len(m) == 1 {
+		for _, mv := range m {
+			v = mv
+		}
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// Function decodes typed value received from the target.
+func decodeValue(tv *gpb.TypedValue) (any, error) {
+	switch v := tv.GetValue().(type) {
+	case *gpb.TypedValue_JsonIetfVal:
+		return decodeJSON(v.JsonIetfVal)
+	case *gpb.TypedValue_JsonVal:
+		return decodeJSON(v.JsonVal)
+	case *gpb.TypedValue_StringVal:
+		return v.StringVal, nil
+	case *gpb.TypedValue_AsciiVal:
+		return v.AsciiVal, nil
+	case *gpb.TypedValue_IntVal:
+		return v.IntVal, nil
+	case *gpb.TypedValue_UintVal:
+		return v.UintVal, nil
+	case *gpb.TypedValue_BoolVal:
+		return v.BoolVal, nil
+	case *gpb.TypedValue_DoubleVal:
+		return v.DoubleVal, nil
+	case *gpb.TypedValue_LeaflistVal:
+		var l []any
+		for _, e := range v.LeaflistVal.GetElement() {
+			ev, err := decodeValue(e)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, ev)
+		}
+		return l, nil
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+// Function decodes JSON keeping numbers as json.Number, so 64-bit counters are not rounded.
+func decodeJSON(b []byte) (any, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v any
+	err := d.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,106 @@
+package gnmi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/azyablov/fat/lib/session/sessiontest"
+	"github.com/google/go-cmp/cmp"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeGNMIServer returns values of the known paths in JSON_IETF encoding.
+type fakeGNMIServer struct {
+	gpb.UnimplementedGNMIServer
+	values map[string]string // JSON value per path.
+	sets   []*gpb.SetRequest
+	fails  int32 // number of subscriptions failed before streaming
+}
+
+func (s *fakeGNMIServer) Get(ctx context.Context, req *gpb.GetRequest) (*gpb.GetResponse, error) {
+	if req.GetEncoding() != gpb.Encoding_JSON_IETF {
+		return nil, status.Errorf(codes.Unimplemented, "unsupported encoding %s", req.GetEncoding())
+	}
+	resp := new(gpb.GetResponse)
+	for _, p := range req.GetPath() {
+		v, ok := s.values[PathString(p)]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "path %s not found", PathString(p))
+		}
+		resp.Notification = append(resp.Notification, &gpb.Notification{
+			Update: []*gpb.Update{{Path: p, Val: &gpb.TypedValue{Value: &gpb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(v)}}}},
+		})
+	}
+	return resp, nil
+}
+
+// Starts fake gNMI server and returns client connected to it.
+func newFakeClient(t *testing.T, srv gpb.GNMIServer) *Client {
+	t.Helper()
+	conn := sessiontest.NewConn(t, func(s *grpc.Server) { gpb.RegisterGNMIServer(s, srv) })
+	return &Client{t: sessiontest.NewTarget(), gc: gpb.NewGNMIClient(conn)}
+}
+
+func TestGetJSON(t *testing.T) {
+	c := newFakeClient(t, &fakeGNMIServer{values: map[string]string{
+		"/":                      `{"srl_nokia-system:system": {"srl_nokia-system-name:name": {"host-name": "leaf1"}}, "srl_nokia-interfaces:interface": [{"name": "ethernet-1/1", "admin-state": "enable"}]}`,
+		"/interface[name=mgmt0]": `{"statistics": {"in-octets": "18446744073709551615", "mtu": 1500}}`,
+		"/system/name/host-name": `"leaf1"`,
+	}})
+
+	m, err := c.GetJSON(DataConfig, "/")
+	if err != nil {
+		t.Fatalf("got an error from GetJSON(): %v", err)
+	}
+	want := map[string]any{
+		"srl_nokia-system:system":        map[string]any{"srl_nokia-system-name:name": map[string]any{"host-name": "leaf1"}},
+		"srl_nokia-interfaces:interface": []any{map[string]any{"name": "ethernet-1/1", "admin-state": "enable"}},
+	}
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Errorf("GetJSON() mismatch (-want +got):\n%s", diff)
+	}
+
+	m, err = c.GetJSON(DataState, "/interface[name=mgmt0]")
+	if err != nil {
+		t.Fatalf("got an error from GetJSON(): %v", err)
+	}
+	if mtu := m["statistics"].(map[string]any)["mtu"]; mtu != json.Number("1500") {
+		t.Errorf("expected number to be kept as json.Number; got: %#v", mtu)
+	}
+
+	// Leaf is put into the tree under its name.
+	m, err = c.GetJSON(DataState, "/system/name/host-name")
+	if err != nil {
+		t.Fatalf("got an error from GetJSON(): %v", err)
+	}
+	if m["host-name"] != "leaf1" {
+		t.Errorf("unexpected leaf value: %v", m)
+	}
+
+	if _, err := c.GetJSON("candidate", "/"); err == nil {
+		t.Errorf("expected an error for unknown data type")
+	}
+	if _, err := c.GetJSON(DataConfig, "/acl"); status.Code(errors.Unwrap(err)) != codes.NotFound {
+		t.Errorf("expected NotFound error; got: %v", err)
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	c := newFakeClient(t, &fakeGNMIServer{values: map[string]string{
+		pathHostname: `{"host-name": "leaf1"}`,
+		pathVersion:  `"v23.3.1-343-gab924f2e64"`,
+	}})
+	v, err := c.GetVersion()
+	if err != nil {
+		t.Fatalf("got an error from GetVersion(): %v", err)
+	}
+	want := &Version{Hostname: "leaf1", SoftwareVersion: "v23.3.1", BuildNumber: "343-gab924f2e64"}
+	if diff := cmp.Diff(want, v); diff != "" {
+		t.Errorf("GetVersion() mismatch (-want +got):\n%s", diff)
+	}
+}
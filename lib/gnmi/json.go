@@ -0,0 +1,100 @@
+package gnmi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/azyablov/fat/lib"
+)
+
+// Returns subtree of JSON tree received via GetJSON() by the path, e.g. /interface[name=ethernet-1/1].
+// Module prefixes of the elements, e.g. srl_nokia-interfaces:interface, are ignored.
+func Subtree(m map[string]any, path string) (any, error) {
+	gp, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	var v any = m
+	for _, e := range gp.GetElem() {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s not found: %s isn't a container", path, e.GetName())
+		}
+		v, ok = lib.JSONChild(obj, e.GetName())
+		if !ok {
+			return nil, fmt.Errorf("%s not found: no %s element", path, e.GetName())
+		}
+		if len(e.GetKey()) == 0 {
+			continue
+		}
+		l, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%s not found: %s isn't a list", path, e.GetName())
+		}
+		v = nil
+		for _, entry := range l {
+			if matchKeys(entry, e.GetKey()) {
+				v = entry
+				break
+			}
+		}
+		if v == nil {
+			return nil, fmt.Errorf("%s not found: no %s entry with keys %v", path, e.GetName(), e.GetKey())
+		}
+	}
+	return v, nil
+}
+
+// Function returns true, if list entry has all the keys, numbers are compared as strings.
+func matchKeys(entry any, keys map[string]string) bool {
+	obj, ok := entry.(map[string]any)
+	if !ok {
+		return false
+	}
+	for k, kv := range keys {
+		v, ok := lib.JSONChild(obj, k)
+		if !ok || fmt.Sprint(v) != kv {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns leaf updates of the update, JSON objects are flattened into paths of their leaves w/o module prefixes,
+// e.g. /interface[name=mgmt0]/statistics {"in-octets": "10"} gives /interface[name=mgmt0]/statistics/in-octets "10".
+// Entries of the lists inside values are skipped, since key names are unknown w/o schema; subscribe to the list instead.
+func Leaves(u *Update) []*Update {
+	var us []*Update
+	var walk func(p string, v any)
+	walk = func(p string, v any) {
+		switch tv := v.(type) {
+		case map[string]any:
+			// Children are walked in order of names w/o module prefixes.
+			names := make(map[string]string, len(tv))
+			keys := make([]string, 0, len(tv))
+			for k := range tv {
+				n := k[strings.LastIndex(k, ":")+1:]
+				names[n] = k
+				keys = append(keys, n)
+			}
+			sort.Strings(keys)
+			for _, n := range keys {
+				walk(strings.TrimSuffix(p, "/")+"/"+n, tv[names[n]])
+			}
+		case []any:
+			for _, e := range tv {
+				switch e.(type) {
+				case map[string]any, []any:
+					return
+				}
+			}
+			// Leaf-list is kept as is.
+			us = append(us, &Update{Path: p, Value: tv})
+		default:
+			us = append(us, &Update{Path: p, Value: tv})
+		}
+	}
+	walk(u.Path, u.Value)
+	return us
+}
@@ -0,0 +1,34 @@
+package gnmi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLeaves(t *testing.T) {
+	u := &Update{
+		Path: "/interface[name=ethernet-1/1]",
+		Value: map[string]any{
+			"srl_nokia-interfaces:oper-state": "up",
+			"statistics":                      map[string]any{"in-octets": json.Number("10"), "out-octets": json.Number("20")},
+			"subinterface":                    []any{map[string]any{"index": json.Number("0")}},
+			"srl_nokia-if-ip:vlan-tagging":    false,
+		},
+	}
+	want := []*Update{
+		{Path: "/interface[name=ethernet-1/1]/oper-state", Value: "up"},
+		{Path: "/interface[name=ethernet-1/1]/statistics/in-octets", Value: json.Number("10")},
+		{Path: "/interface[name=ethernet-1/1]/statistics/out-octets", Value: json.Number("20")},
+		{Path: "/interface[name=ethernet-1/1]/vlan-tagging", Value: false},
+	}
+	if diff := cmp.Diff(want, Leaves(u)); diff != "" {
+		t.Errorf("Leaves() mismatch (-want +got):\n%s", diff)
+	}
+
+	leaf := &Update{Path: "/system/name/host-name", Value: "leaf1"}
+	if diff := cmp.Diff([]*Update{leaf}, Leaves(leaf)); diff != "" {
+		t.Errorf("Leaves() of leaf mismatch (-want +got):\n%s", diff)
+	}
+}
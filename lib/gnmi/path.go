@@ -0,0 +1,119 @@
+package gnmi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Parses string path, e.g. /interface[name=ethernet-1/1]/subinterface[index=0], into gNMI path.
+// Origin could be specified as prefix followed by colon, e.g. cli:/.
+func ParsePath(p string) (*gpb.Path, error) {
+	path, _, err := parsePath(p)
+	return path, err
+}
+
+// Same as ParsePath(), but key names of every element are returned as well in the order they're specified,
+// since gNMI path keeps keys in map.
+func parsePath(p string) (*gpb.Path, [][]string, error) {
+	path := new(gpb.Path)
+	var order [][]string
+	if i := strings.Index(p, ":/"); i > 0 && !strings.ContainsAny(p[:i], "/[") {
+		path.Origin, p = p[:i], p[i+1:]
+	}
+	p = strings.TrimPrefix(p, "/")
+
+	var elem *gpb.PathElem
+	var keyNames []string
+	var name strings.Builder
+	for i := 0; i < len(p); i++ {
+		switch p[i] {
+		case '/':
+			if name.Len() == 0 && elem == nil {
+				return nil, nil, fmt.Errorf("malformed path %s: empty element", p)
+			}
+			if elem == nil {
+				elem = &gpb.PathElem{Name: name.String()}
+			}
+			path.Elem = append(path.Elem, elem)
+			order = append(order, keyNames)
+			elem, keyNames = nil, nil
+			name.Reset()
+		case '[':
+			// Key value could contain / and ], unless escaped, e.g. [name=ethernet-1/1].
+			end := i + 1
+			for ; end < len(p) && p[end] != ']'; end++ {
+				if p[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(p) {
+				return nil, nil, fmt.Errorf("malformed path %s: missed ]", p)
+			}
+			kv := strings.SplitN(p[i+1:end], "=", 2)
+			if len(kv) != 2 || len(kv[0]) == 0 {
+				return nil, nil, fmt.Errorf("malformed path %s: incorrect key %s", p, p[i+1:end])
+			}
+			if elem == nil {
+				if name.Len() == 0 {
+					return nil, nil, fmt.Errorf("malformed path %s: key w/o element name", p)
+				}
+				elem = &gpb.PathElem{Name: name.String(), Key: make(map[string]string)}
+			}
+			keyNames = append(keyNames, kv[0])
+			elem.Key[kv[0]] = strings.NewReplacer(`\]`, "]", `\\`, `\`).Replace(kv[1])
+			i = end
+		default:
+			if elem != nil {
+				return nil, nil, fmt.Errorf("malformed path %s: unexpected %q after key", p, p[i])
+			}
+			name.WriteByte(p[i])
+		}
+	}
+	if elem == nil && name.Len() != 0 {
+		elem = &gpb.PathElem{Name: name.String()}
+	}
+	if elem != nil {
+		path.Elem = append(path.Elem, elem)
+		order = append(order, keyNames)
+	}
+	return path, order, nil
+}
+
+// Returns string representation of gNMI path, keys are sorted.
+func PathString(p *gpb.Path) string {
+	var sb strings.Builder
+	if p.GetOrigin() != "" {
+		sb.WriteString(p.GetOrigin() + ":")
+	}
+	for _, e := range p.GetElem() {
+		sb.WriteString("/" + e.GetName())
+		keys := make([]string, 0, len(e.GetKey()))
+		for k := range e.GetKey() {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := strings.NewReplacer(`\`, `\\`, "]", `\]`).Replace(e.GetKey()[k])
+			sb.WriteString("[" + k + "=" + v + "]")
+		}
+	}
+	if sb.Len() == 0 || (p.GetOrigin() != "" && len(p.GetElem()) == 0) {
+		sb.WriteString("/")
+	}
+	return sb.String()
+}
+
+// Function returns full path of the update concatenating prefix and path.
+func fullPath(prefix *gpb.Path, p *gpb.Path) *gpb.Path {
+	if prefix == nil {
+		return p
+	}
+	fp := &gpb.Path{Origin: prefix.GetOrigin(), Elem: append(append([]*gpb.PathElem{}, prefix.GetElem()...), p.GetElem()...)}
+	if p.GetOrigin() != "" {
+		fp.Origin = p.GetOrigin()
+	}
+	return fp
+}
@@ -0,0 +1,49 @@
+package gnmi
+
+import (
+	"testing"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		p    string
+		path *gpb.Path
+	}{
+		{"/", &gpb.Path{}},
+		{"/system/name/host-name", &gpb.Path{Elem: []*gpb.PathElem{{Name: "system"}, {Name: "name"}, {Name: "host-name"}}}},
+		{"interface[name=ethernet-1/1]/subinterface[index=0]/description", &gpb.Path{Elem: []*gpb.PathElem{
+			{Name: "interface", Key: map[string]string{"name": "ethernet-1/1"}},
+			{Name: "subinterface", Key: map[string]string{"index": "0"}},
+			{Name: "description"},
+		}}},
+		{"/network-instance[name=default]/protocols/bgp/neighbor[network-instance=a\\]b][peer-address=10.0.0.1]", &gpb.Path{Elem: []*gpb.PathElem{
+			{Name: "network-instance", Key: map[string]string{"name": "default"}},
+			{Name: "protocols"},
+			{Name: "bgp"},
+			{Name: "neighbor", Key: map[string]string{"peer-address": "10.0.0.1", "network-instance": "a]b"}},
+		}}},
+		{"cli:/", &gpb.Path{Origin: "cli"}},
+	}
+	for _, tt := range tests {
+		path, err := ParsePath(tt.p)
+		if err != nil {
+			t.Errorf("ParsePath(%s) returned an error: %v", tt.p, err)
+			continue
+		}
+		if !proto.Equal(path, tt.path) {
+			t.Errorf("ParsePath(%s) = %v; want: %v", tt.p, path, tt.path)
+		}
+		if tt.p[0] == '/' && PathString(path) != tt.p {
+			t.Errorf("PathString(ParsePath(%s)) = %s", tt.p, PathString(path))
+		}
+	}
+
+	for _, p := range []string{"/a//b", "/a[name=b", "/[name=b]", "/a[=b]", "/a[name=b]c"} {
+		if _, err := ParsePath(p); err == nil {
+			t.Errorf("ParsePath(%s) expected to fail", p)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package gnmi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Origin of the paths SR Linux applies CLI commands for, value is the text of commands.
+const OriginCLI = "cli"
+
+// Changes are applied by the target in single Set transaction: deletes first, then replaces and updates.
+type Changes struct {
+	Deletes  []string       // Paths to delete.
+	Replaces map[string]any // Path to value replacing the existing one, value is encoded as JSON_IETF.
+	Updates  map[string]any // Path to value merged with the existing one, value is encoded as JSON_IETF.
+	CLI      string         // CLI commands applied via cli origin, e.g. info config, see InfoReplace().
+}
+
+// Applies changes to the target config.
+func Set(t *lib.SRLTarget, ch *Changes) error {
+	return session.WithSession(t, *t.PortgNMI, func(s *session.Session) error {
+		return NewClient(s).Set(ch)
+	})
+}
+
+// Applies changes to the target config.
+func (c *Client) Set(ch *Changes) error {
+	req, err := setRequest(ch)
+	if err != nil {
+		return err
+	}
+	// Set is not retried, since it's unknown whether the transaction was committed.
+	ctx, cancel := context.WithTimeout(context.Background(), *c.t.Timeout)
+	defer cancel()
+	_, err = c.gc.Set(ctx, req)
+	if err != nil {
+		return fmt.Errorf("can't exec Set request: %w", err)
+	}
+	return nil
+}
+
+// Function builds Set request of the changes, paths are sorted to keep the order of operations stable.
+func setRequest(ch *Changes) (*gpb.SetRequest, error) {
+	req := new(gpb.SetRequest)
+	for _, p := range ch.Deletes {
+		gp, err := ParsePath(p)
+		if err != nil {
+			return nil, err
+		}
+		req.Delete = append(req.Delete, gp)
+	}
+	var err error
+	req.Replace, err = updates(ch.Replaces)
+	if err != nil {
+		return nil, err
+	}
+	req.Update, err = updates(ch.Updates)
+	if err != nil {
+		return nil, err
+	}
+	if ch.CLI != "" {
+		req.Update = append(req.Update, &gpb.Update{
+			Path: &gpb.Path{Origin: OriginCLI},
+			Val:  &gpb.TypedValue{Value: &gpb.TypedValue_AsciiVal{AsciiVal: ch.CLI}},
+		})
+	}
+	if len(req.Delete)+len(req.Replace)+len(req.Update) == 0 {
+		return nil, fmt.Errorf("no changes to apply")
+	}
+	return req, nil
+}
+
+// Function encodes values of the paths as JSON_IETF updates.
+func updates(m map[string]any) ([]*gpb.Update, error) {
+	paths := make([]string, 0, len(m))
+	for p := range m {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var us []*gpb.Update
+	for _, p := range paths {
+		gp, err := ParsePath(p)
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(m[p])
+		if err != nil {
+			return nil, fmt.Errorf("can't encode value of %s: %s", p, err)
+		}
+		us = append(us, &gpb.Update{Path: gp, Val: &gpb.TypedValue{Value: &gpb.TypedValue_JsonIetfVal{JsonIetfVal: b}}})
+	}
+	return us, nil
+}
+
+// Returns CLI commands replacing the subtree of info config found by the keys of nested blocks,
+// e.g. "interface ethernet-1/1" or "system", "tls". The whole config is replaced, if no keys provided,
+// but only if it has system gnmi-server block, otherwise the target would be locked out of gNMI access,
+// e.g. with config saved w/o clab management settings.
+func InfoReplace(info string, keys ...string) (string, error) {
+	root, err := lib.NewInfoObject(info)
+	if err != nil {
+		return "", err
+	}
+	if len(keys) == 0 {
+		if findBlock(root, "system", "gnmi-server") == nil {
+			return "", fmt.Errorf("info config has no system gnmi-server block, replacing the whole config would disable gNMI; replace paths instead")
+		}
+		return "delete /\n" + info, nil
+	}
+
+	o := findBlock(root, keys...)
+	if o == nil {
+		return "", fmt.Errorf("block %s not found in info config", strings.Join(keys, " "))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("delete / " + strings.Join(keys, " ") + "\n")
+	// Parent blocks are opened and closed around the subtree, so it's applied from the root.
+	for _, k := range keys[:len(keys)-1] {
+		sb.WriteString(k + " {\n")
+	}
+	sb.WriteString(o.Block(info) + "\n")
+	for range keys[:len(keys)-1] {
+		sb.WriteString("}\n")
+	}
+	return sb.String(), nil
+}
+
+// Function returns nested block of info tree found by the keys, nil if it's missed.
+func findBlock(o *lib.InfoObject, keys ...string) *lib.InfoObject {
+	for _, k := range keys {
+		var found *lib.InfoObject
+		for _, c := range o.Chlds {
+			if c.Key == k {
+				found = c
+				break
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		o = found
+	}
+	return o
+}
+
+// Returns keys of info config blocks corresponding to the path, e.g. "interface ethernet-1/1", "subinterface 0"
+// for /interface[name=ethernet-1/1]/subinterface[index=0]. info prints values of multiple keys in YANG key order,
+// so they should be specified in the path in the same order. Values are quoted the way info does.
+func InfoKeys(p string) ([]string, error) {
+	gp, order, err := parsePath(p)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for i, e := range gp.GetElem() {
+		key := e.GetName()
+		for _, n := range order[i] {
+			key += " " + infoValue(e.GetKey()[n])
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Function returns value as info prints it: quoted, if it's empty or contains spaces or special characters.
+func infoValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, " \t\"\\{};#[]") {
+		return v
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
@@ -0,0 +1,172 @@
+package gnmi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+const sampleInfo = `    interface ethernet-1/1 {
+        admin-state enable
+    }
+    system {
+        tls {
+            server-profile clab-profile {
+                authenticate-client false
+            }
+        }
+        lldp {
+            admin-state enable
+        }
+    }
+`
+
+// Set records requests received by the fake server.
+func (s *fakeGNMIServer) Set(ctx context.Context, req *gpb.SetRequest) (*gpb.SetResponse, error) {
+	s.sets = append(s.sets, req)
+	return &gpb.SetResponse{}, nil
+}
+
+func TestSet(t *testing.T) {
+	srv := &fakeGNMIServer{}
+	c := newFakeClient(t, srv)
+
+	err := c.Set(&Changes{
+		Deletes:  []string{"/interface[name=ethernet-1/2]"},
+		Replaces: map[string]any{"/system/lldp": map[string]any{"admin-state": "enable"}},
+		Updates:  map[string]any{"/interface[name=ethernet-1/1]/description": "uplink", "/interface[name=ethernet-1/1]/mtu": json.Number("9000")},
+		CLI:      "system banner login-banner lab",
+	})
+	if err != nil {
+		t.Fatalf("got an error from Set(): %v", err)
+	}
+	if len(srv.sets) != 1 {
+		t.Fatalf("expected single Set request; got: %d", len(srv.sets))
+	}
+	req := srv.sets[0]
+	if len(req.GetDelete()) != 1 || PathString(req.GetDelete()[0]) != "/interface[name=ethernet-1/2]" {
+		t.Errorf("incorrect deletes: %v", req.GetDelete())
+	}
+	if len(req.GetReplace()) != 1 || string(req.GetReplace()[0].GetVal().GetJsonIetfVal()) != `{"admin-state":"enable"}` {
+		t.Errorf("incorrect replaces: %v", req.GetReplace())
+	}
+	var got []string
+	for _, u := range req.GetUpdate() {
+		got = append(got, PathString(u.GetPath())+" "+string(u.GetVal().GetJsonIetfVal())+u.GetVal().GetAsciiVal())
+	}
+	want := []string{
+		`/interface[name=ethernet-1/1]/description "uplink"`,
+		`/interface[name=ethernet-1/1]/mtu 9000`,
+		`cli:/ system banner login-banner lab`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("updates mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := c.Set(&Changes{}); err == nil {
+		t.Errorf("expected an error for empty changes")
+	}
+}
+
+func TestInfoReplace(t *testing.T) {
+	cmds, err := InfoReplace(sampleInfo, "system", "tls")
+	if err != nil {
+		t.Fatalf("got an error from InfoReplace(): %v", err)
+	}
+	want := `delete / system tls
+system {
+        tls {
+            server-profile clab-profile {
+                authenticate-client false
+            }
+        }
+}
+`
+	if diff := cmp.Diff(want, cmds); diff != "" {
+		t.Errorf("InfoReplace() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := InfoReplace(sampleInfo); err == nil {
+		t.Errorf("expected an error for the whole config w/o gnmi-server")
+	}
+	withGNMI := strings.Replace(sampleInfo, "        lldp {", "        gnmi-server {\n            admin-state enable\n        }\n        lldp {", 1)
+	cmds, err = InfoReplace(withGNMI)
+	if err != nil || !strings.HasPrefix(cmds, "delete /\n    interface ethernet-1/1 {") {
+		t.Errorf("expected the whole config to be replaced; got: %q, %v", cmds, err)
+	}
+	if _, err := InfoReplace(sampleInfo, "system", "aaa"); err == nil {
+		t.Errorf("expected an error for missed block")
+	}
+
+	vrf := sampleInfo + `    network-instance "lab vrf" {
+        type ip-vrf
+    }
+`
+	keys, err := InfoKeys(`/network-instance[name=lab vrf]`)
+	if err != nil {
+		t.Fatalf("got an error from InfoKeys(): %v", err)
+	}
+	cmds, err = InfoReplace(vrf, keys...)
+	if err != nil || !strings.HasPrefix(cmds, `delete / network-instance "lab vrf"`) {
+		t.Errorf("expected block with quoted key to be replaced; got: %q, %v", cmds, err)
+	}
+}
+
+func TestInfoKeys(t *testing.T) {
+	testData := []struct {
+		testName string
+		path     string
+		expKeys  []string
+	}{
+		{testName: "Single keys", path: "/interface[name=ethernet-1/1]/subinterface[index=0]", expKeys: []string{"interface ethernet-1/1", "subinterface 0"}},
+		{testName: "Multiple keys in path order", path: "/routing-policy/prefix-set[name=lo]/prefix[mask-length-range=exact][ip-prefix=10.0.0.0/8]", expKeys: []string{"routing-policy", "prefix-set lo", "prefix exact 10.0.0.0/8"}},
+		{testName: "Quoted values", path: `/network-instance[name=lab vrf]/description[text=say "hi"]`, expKeys: []string{`network-instance "lab vrf"`, `description "say \"hi\""`}},
+	}
+
+	for _, d := range testData {
+		t.Run(d.testName, func(t *testing.T) {
+			keys, err := InfoKeys(d.path)
+			if err != nil {
+				t.Fatalf("got an error from InfoKeys(): %v", err)
+			}
+			if diff := cmp.Diff(d.expKeys, keys); diff != "" {
+				t.Errorf("InfoKeys() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSubtree(t *testing.T) {
+	cfg := map[string]any{
+		"srl_nokia-interfaces:interface": []any{
+			map[string]any{"name": "ethernet-1/1", "subinterface": []any{map[string]any{"index": json.Number("0"), "admin-state": "enable"}}},
+		},
+		"srl_nokia-system:system": map[string]any{"srl_nokia-lldp:lldp": map[string]any{"admin-state": "enable"}},
+	}
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"/system/lldp", map[string]any{"admin-state": "enable"}},
+		{"/interface[name=ethernet-1/1]/subinterface[index=0]/admin-state", "enable"},
+	}
+	for _, tt := range tests {
+		v, err := Subtree(cfg, tt.path)
+		if err != nil {
+			t.Errorf("Subtree(%s) returned an error: %v", tt.path, err)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, v); diff != "" {
+			t.Errorf("Subtree(%s) mismatch (-want +got):\n%s", tt.path, diff)
+		}
+	}
+	for _, p := range []string{"/acl", "/interface[name=ethernet-1/2]", "/system[name=a]", "/system/lldp/admin-state/x"} {
+		if _, err := Subtree(cfg, p); err == nil {
+			t.Errorf("Subtree(%s) expected to fail", p)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package gnmi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session"
+)
+
+// Name of the transport config source is registered under.
+const SourceName = "gnmi"
+
+const pathChassisType = "/platform/chassis/type"
+
+func init() {
+	lib.RegisterConfigSource(SourceName, NewConfigSource)
+}
+
+// ConfigSource extracts config in JSON_IETF encoding via gNMI Get.
+type ConfigSource struct {
+	s    *session.Session
+	c    *Client
+	stop func() bool // stops closing the session, once ctx is done
+}
+
+// Creates config source over new session to the gNMI port of the target, calls in flight are aborted
+// by closing the session, once ctx is done.
+func NewConfigSource(ctx context.Context, t *lib.SRLTarget) (lib.ConfigSource, error) {
+	s, err := session.NewWithPort(t, *t.PortgNMI)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigSource{s: s, c: NewClient(s), stop: lib.AfterFunc(ctx, func() { s.Close() })}, nil
+}
+
+// Returns hostname, chassis type and software version of the target.
+func (s *ConfigSource) ShowVersion() (*lib.SystemVersion, error) {
+	v, err := s.c.GetVersion()
+	if err != nil {
+		return nil, session.SourceError(err)
+	}
+	sv := &lib.SystemVersion{Hostname: v.Hostname, SoftwareVersion: v.SoftwareVersion}
+	// Chassis type is informational, so it's left empty if the platform doesn't report it.
+	us, err := s.c.Get(DataState, pathChassisType)
+	if err == nil && len(us) == 1 {
+		sv.ChassisType, _ = leafString(us[0].Value)
+	}
+	return sv, nil
+}
+
+// Returns the whole running config as indented JSON.
+func (s *ConfigSource) Info() (string, error) {
+	cfg, err := s.c.GetJSON(DataConfig, "/")
+	if err != nil {
+		return "", session.SourceError(err)
+	}
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("can't marshal config: %s", err)
+	}
+	return string(b) + "\n", nil
+}
+
+// Returns lib.ConfigFormatJSON.
+func (s *ConfigSource) Format() string {
+	return lib.ConfigFormatJSON
+}
+
+// Closes the session, unless it's closed already on ctx done.
+func (s *ConfigSource) Cleanup() error {
+	if !s.stop() {
+		return nil
+	}
+	return s.s.Close()
+}
@@ -0,0 +1,40 @@
+package gnmi
+
+import (
+	"testing"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConfigSource(t *testing.T) {
+	values := map[string]string{
+		pathHostname: `{"host-name": "leaf1"}`,
+		pathVersion:  `"v23.3.1-343-gab924f2e64"`,
+		"/":          `{"srl_nokia-system:system": {"srl_nokia-system-name:name": {"host-name": "leaf1"}}}`,
+	}
+	src := &ConfigSource{c: newFakeClient(t, &fakeGNMIServer{values: values})}
+
+	// Chassis type isn't reported by the fake server.
+	v, err := src.ShowVersion()
+	if err != nil {
+		t.Fatalf("got an error from ShowVersion(): %v", err)
+	}
+	if diff := cmp.Diff(&lib.SystemVersion{Hostname: "leaf1", SoftwareVersion: "v23.3.1"}, v); diff != "" {
+		t.Errorf("ShowVersion() mismatch (-want +got):\n%s", diff)
+	}
+	values[pathChassisType] = `"7220 IXR-D2"`
+	v, err = src.ShowVersion()
+	if err != nil || v.ChassisType != "7220 IXR-D2" {
+		t.Errorf("expected chassis type 7220 IXR-D2; got: %+v, %v", v, err)
+	}
+
+	cfg, err := src.Info()
+	if err != nil {
+		t.Fatalf("got an error from Info(): %v", err)
+	}
+	want := "{\n  \"srl_nokia-system:system\": {\n    \"srl_nokia-system-name:name\": {\n      \"host-name\": \"leaf1\"\n    }\n  }\n}\n"
+	if diff := cmp.Diff(want, cfg); diff != "" {
+		t.Errorf("Info() mismatch (-want +got):\n%s", diff)
+	}
+}
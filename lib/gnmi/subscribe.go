@@ -0,0 +1,180 @@
+package gnmi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Subscription modes.
+const (
+	ModeOnChange      = "on_change"
+	ModeSample        = "sample"
+	ModeTargetDefined = "target_defined"
+)
+
+var subscriptionModes = map[string]gpb.SubscriptionMode{
+	ModeOnChange:      gpb.SubscriptionMode_ON_CHANGE,
+	ModeSample:        gpb.SubscriptionMode_SAMPLE,
+	ModeTargetDefined: gpb.SubscriptionMode_TARGET_DEFINED,
+}
+
+// Subscription describes the path to stream.
+type Subscription struct {
+	Path           string
+	Mode           string        // ModeOnChange, ModeSample or ModeTargetDefined.
+	SampleInterval time.Duration // Interval between samples in ModeSample.
+}
+
+// Notification is the set of changes received from the target at once.
+// Notification with Sync set marks the end of initial state and carries no changes.
+type Notification struct {
+	Timestamp time.Time
+	Updates   []*Update
+	Deletes   []string
+	Sync      bool
+}
+
+// NotificationFunc is called for every notification received, returned error stops the subscription.
+type NotificationFunc func(n *Notification) error
+
+// BrokenFunc is called with the error the stream is broken with and the delay before it's re-established.
+type BrokenFunc func(err error, d time.Duration)
+
+// Checks paths and modes of the subscriptions, so they could be reported before subscribing.
+func CheckSubscriptions(subs []*Subscription) error {
+	_, err := subscriptionList(subs)
+	return err
+}
+
+// Function returns STREAM subscription list of the subscriptions.
+func subscriptionList(subs []*Subscription) (*gpb.SubscriptionList, error) {
+	if len(subs) == 0 {
+		return nil, fmt.Errorf("no paths to subscribe to")
+	}
+	sl := &gpb.SubscriptionList{Mode: gpb.SubscriptionList_STREAM, Encoding: gpb.Encoding_JSON_IETF}
+	for _, s := range subs {
+		gp, err := ParsePath(s.Path)
+		if err != nil {
+			return nil, err
+		}
+		mode, ok := subscriptionModes[s.Mode]
+		if !ok {
+			return nil, fmt.Errorf("unknown subscription mode %s", s.Mode)
+		}
+		sl.Subscription = append(sl.Subscription, &gpb.Subscription{Path: gp, Mode: mode, SampleInterval: uint64(s.SampleInterval)})
+	}
+	return sl, nil
+}
+
+// Same as Subscribe(), but the stream is re-established with backoff of the target retry policy, once it's broken.
+// Backoff starts over, if the stream was up, i.e. initial state was received. broken is called before reconnecting, if not nil.
+// Blocks till ctx is cancelled or fn returns error, incorrect subscriptions are reported at once.
+func (c *Client) SubscribeRetry(ctx context.Context, subs []*Subscription, fn NotificationFunc, broken BrokenFunc) error {
+	err := CheckSubscriptions(subs)
+	if err != nil {
+		return err
+	}
+	p := c.t.Retry
+	if p == nil {
+		p = lib.NewRetryPolicy(1, time.Second)
+	}
+
+	var fnErr error
+	for n := 0; ; {
+		synced := false
+		err := c.Subscribe(ctx, subs, func(gn *Notification) error {
+			if gn.Sync {
+				synced = true
+			}
+			fnErr = fn(gn)
+			return fnErr
+		})
+		if fnErr != nil {
+			return fnErr
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if synced {
+			// Stream was up, so backoff starts over.
+			n = 0
+		}
+		n++
+		d := p.Backoff(n)
+		if broken != nil {
+			broken(err, d)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+}
+
+// Subscribes to the paths in STREAM mode and calls fn for every notification received.
+// Blocks till ctx is cancelled, fn returns error or the stream is broken.
+func (c *Client) Subscribe(ctx context.Context, subs []*Subscription, fn NotificationFunc) error {
+	sl, err := subscriptionList(subs)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.gc.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("can't exec Subscribe request: %w", err)
+	}
+	err = stream.Send(&gpb.SubscribeRequest{Request: &gpb.SubscribeRequest_Subscribe{Subscribe: sl}})
+	if err != nil {
+		return fmt.Errorf("can't send SubscribeRequest: %w", err)
+	}
+
+	for {
+		sResp, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("can't get SubscribeResponse: %w", err)
+		}
+		var n *Notification
+		switch r := sResp.GetResponse().(type) {
+		case *gpb.SubscribeResponse_SyncResponse:
+			n = &Notification{Sync: true}
+		case *gpb.SubscribeResponse_Update:
+			n, err = notification(r.Update)
+			if err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+		err = fn(n)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Function decodes gNMI notification.
+func notification(gn *gpb.Notification) (*Notification, error) {
+	n := &Notification{Timestamp: time.Unix(0, gn.GetTimestamp())}
+	for _, u := range gn.GetUpdate() {
+		p := PathString(fullPath(gn.GetPrefix(), u.GetPath()))
+		v, err := decodeValue(u.GetVal())
+		if err != nil {
+			return nil, fmt.Errorf("can't decode value of %s: %w", p, err)
+		}
+		n.Updates = append(n.Updates, &Update{Path: p, Value: v})
+	}
+	for _, d := range gn.GetDelete() {
+		n.Deletes = append(n.Deletes, PathString(fullPath(gn.GetPrefix(), d)))
+	}
+	return n, nil
+}
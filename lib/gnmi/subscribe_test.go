@@ -0,0 +1,132 @@
+package gnmi
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Subscribe sends the value of every subscribed path, sync response and then the same values again as changes.
+func (s *fakeGNMIServer) Subscribe(stream gpb.GNMI_SubscribeServer) error {
+	if atomic.AddInt32(&s.fails, -1) >= 0 {
+		return status.Error(codes.Unavailable, "target is booting")
+	}
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	sl := req.GetSubscribe()
+	if sl.GetMode() != gpb.SubscriptionList_STREAM {
+		return errors.New("STREAM mode expected")
+	}
+	send := func(ts int64) error {
+		for _, sub := range sl.GetSubscription() {
+			n := &gpb.Notification{
+				Timestamp: ts,
+				Prefix:    &gpb.Path{Elem: sub.GetPath().GetElem()[:1]},
+				Update: []*gpb.Update{{
+					Path: &gpb.Path{Elem: sub.GetPath().GetElem()[1:]},
+					Val:  &gpb.TypedValue{Value: &gpb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(s.values[PathString(sub.GetPath())])}},
+				}},
+			}
+			if err := stream.Send(&gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_Update{Update: n}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := send(1); err != nil {
+		return err
+	}
+	if err := stream.Send(&gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_SyncResponse{SyncResponse: true}}); err != nil {
+		return err
+	}
+	if err := send(2); err != nil {
+		return err
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func TestSubscribe(t *testing.T) {
+	c := newFakeClient(t, &fakeGNMIServer{values: map[string]string{
+		"/system/configuration/commit[id=1]": `{"username": "admin"}`,
+	}})
+	errStop := errors.New("stop")
+
+	var ns []*Notification
+	err := c.Subscribe(context.Background(), []*Subscription{{Path: "/system/configuration/commit[id=1]", Mode: ModeOnChange}}, func(n *Notification) error {
+		ns = append(ns, n)
+		if len(ns) == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop {
+		t.Fatalf("expected subscription to be stopped by callback; got: %v", err)
+	}
+	if !ns[1].Sync || ns[0].Sync || ns[2].Sync {
+		t.Errorf("sync is expected after initial state only: %+v", ns)
+	}
+	u := ns[2].Updates[0]
+	if u.Path != "/system/configuration/commit[id=1]" || u.Value.(map[string]any)["username"] != "admin" || !ns[2].Timestamp.Equal(time.Unix(0, 2)) {
+		t.Errorf("incorrect notification: %+v, %+v", ns[2], u)
+	}
+
+	// Cancelled context stops the subscription.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = c.Subscribe(ctx, []*Subscription{{Path: "/system/configuration/commit[id=1]", Mode: ModeOnChange}}, func(n *Notification) error { return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded; got: %v", err)
+	}
+
+	if err := c.Subscribe(context.Background(), []*Subscription{{Path: "/", Mode: "poll"}}, nil); err == nil {
+		t.Errorf("expected an error for unknown mode")
+	}
+}
+
+func TestSubscribeRetry(t *testing.T) {
+	c := newFakeClient(t, &fakeGNMIServer{values: map[string]string{
+		"/system/configuration/commit[id=1]": `{"username": "admin"}`,
+	}, fails: 2})
+	c.t.Retry = &lib.RetryPolicy{InitialBackoff: time.Millisecond}
+	subs := []*Subscription{{Path: "/system/configuration/commit[id=1]", Mode: ModeOnChange}}
+	errStop := errors.New("stop")
+
+	broken := 0
+	err := c.SubscribeRetry(context.Background(), subs, func(n *Notification) error {
+		if n.Sync {
+			return errStop
+		}
+		return nil
+	}, func(err error, d time.Duration) {
+		broken++
+	})
+	if err != errStop {
+		t.Fatalf("expected subscription to be stopped by callback; got: %v", err)
+	}
+	if broken != 2 {
+		t.Errorf("expected 2 reconnects; got: %d", broken)
+	}
+
+	// Cancelled context stops the subscription.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = c.SubscribeRetry(ctx, subs, func(n *Notification) error { return nil }, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded; got: %v", err)
+	}
+
+	// Incorrect subscriptions aren't retried.
+	if err := c.SubscribeRetry(context.Background(), []*Subscription{{Path: "/", Mode: "poll"}}, nil, nil); err == nil {
+		t.Errorf("expected an error for unknown mode")
+	}
+}
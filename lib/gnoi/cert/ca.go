@@ -0,0 +1,104 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+)
+
+// CA signs certificates for the targets, usually it's the one clab or lab PKI issued certificates with.
+type CA struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+	PEM  []byte // CA certificate in PEM format, it's sent to the target as trust bundle.
+}
+
+// Loads CA certificate and private key from PEM files, e.g. the same root CA file used with -rootCA.
+// Private key could be in PKCS#1, PKCS#8 or SEC 1 (EC) format.
+func LoadCA(certFile string, keyFile string) (*CA, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't read CA certificate: %s", err)
+	}
+	b, _ := pem.Decode(certPEM)
+	if b == nil || b.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM encoded certificate found in %s", certFile)
+	}
+	c, err := x509.ParseCertificate(b.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse CA certificate: %s", err)
+	}
+	if !c.IsCA {
+		return nil, fmt.Errorf("certificate %s isn't CA one", c.Subject)
+	}
+
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't read CA private key: %s", err)
+	}
+	b, _ = pem.Decode(keyPEM)
+	if b == nil {
+		return nil, fmt.Errorf("no PEM encoded private key found in %s", keyFile)
+	}
+	key, err := parsePrivateKey(b.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &CA{Cert: c, Key: key, PEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})}, nil
+}
+
+// Function parses DER encoded private key trying all formats openssl produces.
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if k, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return k, nil
+	}
+	if k, err := x509.ParseECPrivateKey(der); err == nil {
+		return k, nil
+	}
+	k, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse private key: %s", err)
+	}
+	switch k := k.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", k)
+	}
+}
+
+// Signs certificate for the public key using template subject and SANs. Returns certificate in PEM format.
+func (ca *CA) Sign(tmpl *x509.Certificate, pub crypto.PublicKey, validity time.Duration) ([]byte, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("can't generate serial number: %s", err)
+	}
+	c := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      tmpl.Subject,
+		DNSNames:     tmpl.DNSNames,
+		IPAddresses:  tmpl.IPAddresses,
+		// Tolerating clock skew between the host and the target.
+		NotBefore:   time.Now().Add(-5 * time.Minute),
+		NotAfter:    time.Now().Add(validity),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, c, ca.Cert, pub, ca.Key)
+	if err != nil {
+		return nil, fmt.Errorf("can't sign certificate: %s", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
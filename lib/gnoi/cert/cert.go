@@ -0,0 +1,379 @@
+// Package cert wraps gNOI CertificateManagement service RPCs to install and rotate TLS certificates of the target.
+package cert
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session"
+	"github.com/openconfig/gnoi/cert"
+)
+
+// Defaults applied to zero Options fields.
+const (
+	DefaultKeySize  = 2048
+	DefaultValidity = 365 * 24 * time.Hour
+)
+
+// Client executes gNOI CertificateManagement RPCs over the shared session.
+type Client struct {
+	t  *lib.SRLTarget
+	cc cert.CertificateManagementClient
+}
+
+// Creates CertificateManagement client over provided session.
+func NewClient(s *session.Session) *Client {
+	return &Client{t: s.Target(), cc: cert.NewCertificateManagementClient(s.Conn())}
+}
+
+// Options describe certificate to be installed or rotated.
+type Options struct {
+	CertificateID string        // ID of the certificate, SR Linux uses TLS server profile name, e.g. clab-profile.
+	CommonName    string        // Subject common name, usually target hostname.
+	DNSNames      []string      // DNS SANs.
+	IPAddresses   []net.IP      // IP SANs, the first one is passed to the target within CSR params.
+	KeySize       int           // RSA key size, DefaultKeySize if zero.
+	Validity      time.Duration // Certificate validity, DefaultValidity if zero.
+	LocalKey      bool          // Generate key pair locally instead of asking the target for CSR.
+}
+
+// CertInfo describes certificate installed on the target.
+type CertInfo struct {
+	ID               string
+	Certificate      *x509.Certificate
+	ModificationTime time.Time
+}
+
+// Installs new certificate signed by CA on the target.
+func Install(t *lib.SRLTarget, ca *CA, opts *Options) error {
+	return session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		return NewClient(s).Install(ca, opts)
+	})
+}
+
+// Installs new certificate signed by CA on the target.
+func (c *Client) Install(ca *CA, opts *Options) error {
+	if err := opts.check(); err != nil {
+		return err
+	}
+	// Creating context cancelled on inactivity.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idle := time.AfterFunc(*c.t.Timeout, cancel)
+	defer idle.Stop()
+
+	iStream, err := c.cc.Install(ctx)
+	if err != nil {
+		return fmt.Errorf("can't exec Install request: %w", err)
+	}
+	load, err := loadRequest(ca, opts, func(req *cert.GenerateCSRRequest) (*cert.CSR, error) {
+		err := iStream.Send(&cert.InstallCertificateRequest{InstallRequest: &cert.InstallCertificateRequest_GenerateCsr{GenerateCsr: req}})
+		if err != nil {
+			return nil, fmt.Errorf("can't send GenerateCSR request: %w", err)
+		}
+		iResp, err := iStream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("can't get GenerateCSR response: %w", err)
+		}
+		idle.Reset(*c.t.Timeout)
+		return iResp.GetGeneratedCsr().GetCsr(), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = iStream.Send(&cert.InstallCertificateRequest{InstallRequest: &cert.InstallCertificateRequest_LoadCertificate{LoadCertificate: load}})
+	if err != nil {
+		return fmt.Errorf("can't send LoadCertificate request: %w", err)
+	}
+	iResp, err := iStream.Recv()
+	if err != nil {
+		return fmt.Errorf("can't get LoadCertificate response: %w", err)
+	}
+	if iResp.GetLoadCertificate() == nil {
+		return fmt.Errorf("unexpected response to LoadCertificate request: %v", iResp)
+	}
+	return iStream.CloseSend()
+}
+
+// Rotates existing certificate on the target replacing it with the new one signed by CA.
+// If verify isn't nil, it's called once the new certificate is loaded, e.g. to establish new session with it,
+// rotation is finalized only if verify succeeds, otherwise the target rolls back to the old certificate.
+func Rotate(t *lib.SRLTarget, ca *CA, opts *Options, verify func() error) error {
+	return session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		return NewClient(s).Rotate(ca, opts, verify)
+	})
+}
+
+// Rotates existing certificate on the target, see Rotate().
+func (c *Client) Rotate(ca *CA, opts *Options, verify func() error) error {
+	if err := opts.check(); err != nil {
+		return err
+	}
+	// Creating context cancelled on inactivity, cancellation before finalization rolls rotation back.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idle := time.AfterFunc(*c.t.Timeout, cancel)
+	defer idle.Stop()
+
+	rStream, err := c.cc.Rotate(ctx)
+	if err != nil {
+		return fmt.Errorf("can't exec Rotate request: %w", err)
+	}
+	load, err := loadRequest(ca, opts, func(req *cert.GenerateCSRRequest) (*cert.CSR, error) {
+		err := rStream.Send(&cert.RotateCertificateRequest{RotateRequest: &cert.RotateCertificateRequest_GenerateCsr{GenerateCsr: req}})
+		if err != nil {
+			return nil, fmt.Errorf("can't send GenerateCSR request: %w", err)
+		}
+		rResp, err := rStream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("can't get GenerateCSR response: %w", err)
+		}
+		idle.Reset(*c.t.Timeout)
+		return rResp.GetGeneratedCsr().GetCsr(), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = rStream.Send(&cert.RotateCertificateRequest{RotateRequest: &cert.RotateCertificateRequest_LoadCertificate{LoadCertificate: load}})
+	if err != nil {
+		return fmt.Errorf("can't send LoadCertificate request: %w", err)
+	}
+	rResp, err := rStream.Recv()
+	if err != nil {
+		return fmt.Errorf("can't get LoadCertificate response: %w", err)
+	}
+	if rResp.GetLoadCertificate() == nil {
+		return fmt.Errorf("unexpected response to LoadCertificate request: %v", rResp)
+	}
+
+	if verify != nil {
+		// Verification could take longer than target timeout.
+		idle.Stop()
+		err = verify()
+		if err != nil {
+			return fmt.Errorf("new certificate verification failed, rotation isn't finalized: %w", err)
+		}
+		idle.Reset(*c.t.Timeout)
+	}
+
+	err = rStream.Send(&cert.RotateCertificateRequest{RotateRequest: &cert.RotateCertificateRequest_FinalizeRotation{FinalizeRotation: &cert.FinalizeRequest{}}})
+	if err != nil {
+		return fmt.Errorf("can't send FinalizeRotation request: %w", err)
+	}
+	err = rStream.CloseSend()
+	if err != nil {
+		return fmt.Errorf("can't close Rotate stream: %w", err)
+	}
+	// The target closes the stream once rotation is finalized.
+	_, err = rStream.Recv()
+	if err != io.EOF {
+		return fmt.Errorf("can't finalize rotation: %v", err)
+	}
+	return nil
+}
+
+// Returns certificates installed on the target.
+func GetCertificates(t *lib.SRLTarget) ([]CertInfo, error) {
+	var certs []CertInfo
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		certs, err = c.GetCertificates()
+		return err
+	})
+	return certs, err
+}
+
+// Returns certificates installed on the target.
+func (c *Client) GetCertificates() ([]CertInfo, error) {
+	var gResp *cert.GetCertificatesResponse
+	err := c.t.Retry.Do(session.Retryable, func() error {
+		// Creating context with timeout.
+		ctx, cancel := context.WithTimeout(context.Background(), *c.t.Timeout)
+		defer cancel()
+
+		var err error
+		gResp, err = c.cc.GetCertificates(ctx, &cert.GetCertificatesRequest{})
+		if err != nil {
+			return fmt.Errorf("can't exec GetCertificates request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	certs := make([]CertInfo, 0, len(gResp.GetCertificateInfo()))
+	for _, ci := range gResp.GetCertificateInfo() {
+		x, err := parseCertificate(ci.GetCertificate().GetCertificate())
+		if err != nil {
+			return nil, fmt.Errorf("certificate %s: %w", ci.GetCertificateId(), err)
+		}
+		certs = append(certs, CertInfo{
+			ID:               ci.GetCertificateId(),
+			Certificate:      x,
+			ModificationTime: time.Unix(0, ci.GetModificationTime()),
+		})
+	}
+	return certs, nil
+}
+
+// Revokes certificates with provided IDs and returns IDs of revoked ones.
+func RevokeCertificates(t *lib.SRLTarget, ids []string) ([]string, error) {
+	var revoked []string
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		revoked, err = c.RevokeCertificates(ids)
+		return err
+	})
+	return revoked, err
+}
+
+// Revokes certificates with provided IDs and returns IDs of revoked ones.
+// Error lists certificates the target failed to revoke.
+func (c *Client) RevokeCertificates(ids []string) ([]string, error) {
+	// Creating context with timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), *c.t.Timeout)
+	defer cancel()
+
+	rResp, err := c.cc.RevokeCertificates(ctx, &cert.RevokeCertificatesRequest{CertificateId: ids})
+	if err != nil {
+		return nil, fmt.Errorf("can't exec RevokeCertificates request: %w", err)
+	}
+	var errs []string
+	for _, e := range rResp.GetCertificateRevocationError() {
+		errs = append(errs, fmt.Sprintf("%s: %s", e.GetCertificateId(), e.GetErrorMessage()))
+	}
+	if len(errs) != 0 {
+		return rResp.GetRevokedCertificateId(), fmt.Errorf("can't revoke certificates: %s", strings.Join(errs, "; "))
+	}
+	return rResp.GetRevokedCertificateId(), nil
+}
+
+// Function validates options and populates defaults.
+func (o *Options) check() error {
+	if o == nil || len(o.CertificateID) == 0 {
+		return fmt.Errorf("certificate ID is mandatory, but missed")
+	}
+	if len(o.CommonName) == 0 {
+		return fmt.Errorf("common name is mandatory, but missed")
+	}
+	if o.KeySize == 0 {
+		o.KeySize = DefaultKeySize
+	}
+	if o.Validity == 0 {
+		o.Validity = DefaultValidity
+	}
+	return nil
+}
+
+// Function returns certificate template built from options.
+func (o *Options) template() *x509.Certificate {
+	return &x509.Certificate{
+		Subject:     pkix.Name{CommonName: o.CommonName},
+		DNSNames:    o.DNSNames,
+		IPAddresses: o.IPAddresses,
+	}
+}
+
+// Function builds LoadCertificate request with the certificate signed by CA.
+// Key pair is generated locally, if requested, otherwise CSR is requested from the target via genCSR.
+func loadRequest(ca *CA, opts *Options, genCSR func(req *cert.GenerateCSRRequest) (*cert.CSR, error)) (*cert.LoadCertificateRequest, error) {
+	load := &cert.LoadCertificateRequest{
+		CertificateId:  opts.CertificateID,
+		CaCertificates: []*cert.Certificate{{Type: cert.CertificateType_CT_X509, Certificate: ca.PEM}},
+	}
+
+	if opts.LocalKey {
+		key, err := rsa.GenerateKey(rand.Reader, opts.KeySize)
+		if err != nil {
+			return nil, fmt.Errorf("can't generate key pair: %s", err)
+		}
+		pubDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("can't marshal public key: %s", err)
+		}
+		certPEM, err := ca.Sign(opts.template(), &key.PublicKey, opts.Validity)
+		if err != nil {
+			return nil, err
+		}
+		load.Certificate = &cert.Certificate{Type: cert.CertificateType_CT_X509, Certificate: certPEM}
+		load.KeyPair = &cert.KeyPair{
+			PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+			PublicKey:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}),
+		}
+		return load, nil
+	}
+
+	params := &cert.CSRParams{
+		Type:       cert.CertificateType_CT_X509,
+		MinKeySize: uint32(opts.KeySize),
+		KeyType:    cert.KeyType_KT_RSA,
+		CommonName: opts.CommonName,
+	}
+	if len(opts.IPAddresses) != 0 {
+		params.IpAddress = opts.IPAddresses[0].String()
+	}
+	csr, err := genCSR(&cert.GenerateCSRRequest{CsrParams: params, CertificateId: opts.CertificateID})
+	if err != nil {
+		return nil, err
+	}
+	req, err := parseCSR(csr.GetCsr())
+	if err != nil {
+		return nil, err
+	}
+	// SANs requested by the target are preserved.
+	tmpl := opts.template()
+	tmpl.DNSNames = append(tmpl.DNSNames, req.DNSNames...)
+	tmpl.IPAddresses = append(tmpl.IPAddresses, req.IPAddresses...)
+	certPEM, err := ca.Sign(tmpl, req.PublicKey, opts.Validity)
+	if err != nil {
+		return nil, err
+	}
+	load.Certificate = &cert.Certificate{Type: cert.CertificateType_CT_X509, Certificate: certPEM}
+	return load, nil
+}
+
+// Function parses CSR received from the target either in PEM or DER format and checks its signature.
+func parseCSR(b []byte) (*x509.CertificateRequest, error) {
+	if len(b) == 0 {
+		return nil, fmt.Errorf("no CSR received from the target")
+	}
+	if p, _ := pem.Decode(b); p != nil {
+		b = p.Bytes
+	}
+	req, err := x509.ParseCertificateRequest(b)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse CSR: %s", err)
+	}
+	err = req.CheckSignature()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSR signature: %s", err)
+	}
+	return req, nil
+}
+
+// Function parses certificate either in PEM or DER format.
+func parseCertificate(b []byte) (*x509.Certificate, error) {
+	if p, _ := pem.Decode(b); p != nil {
+		b = p.Bytes
+	}
+	c, err := x509.ParseCertificate(b)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse certificate: %s", err)
+	}
+	return c, nil
+}
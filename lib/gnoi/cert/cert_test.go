@@ -0,0 +1,227 @@
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib/session/sessiontest"
+	"github.com/openconfig/gnoi/cert"
+	"google.golang.org/grpc"
+)
+
+// Function creates self-signed CA and saves it into temp dir, returns file names of certificate and key.
+func newTestCA(t *testing.T, isCA bool) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "lab root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "root-ca.pem"), filepath.Join(dir, "root-ca-key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// fakeCertServer generates CSR on request and checks loaded certificate is signed by provided CA.
+type fakeCertServer struct {
+	cert.UnimplementedCertificateManagementServer
+	mu        sync.Mutex
+	loaded    *x509.Certificate
+	finalized bool
+}
+
+func (s *fakeCertServer) Rotate(stream cert.CertificateManagement_RotateServer) error {
+	var key *rsa.PrivateKey
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		switch {
+		case req.GetGenerateCsr() != nil:
+			params := req.GetGenerateCsr().GetCsrParams()
+			key, err = rsa.GenerateKey(rand.Reader, int(params.MinKeySize))
+			if err != nil {
+				return err
+			}
+			der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+				Subject:  pkix.Name{CommonName: params.CommonName},
+				DNSNames: []string{"srl1.lab"},
+			}, key)
+			if err != nil {
+				return err
+			}
+			err = stream.Send(&cert.RotateCertificateResponse{RotateResponse: &cert.RotateCertificateResponse_GeneratedCsr{
+				GeneratedCsr: &cert.GenerateCSRResponse{Csr: &cert.CSR{Type: cert.CertificateType_CT_X509, Csr: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})}},
+			}})
+			if err != nil {
+				return err
+			}
+		case req.GetLoadCertificate() != nil:
+			c, err := s.verifyLoad(req.GetLoadCertificate(), key)
+			if err != nil {
+				return err
+			}
+			s.mu.Lock()
+			s.loaded = c
+			s.mu.Unlock()
+			err = stream.Send(&cert.RotateCertificateResponse{RotateResponse: &cert.RotateCertificateResponse_LoadCertificate{LoadCertificate: &cert.LoadCertificateResponse{}}})
+			if err != nil {
+				return err
+			}
+		case req.GetFinalizeRotation() != nil:
+			s.mu.Lock()
+			s.finalized = true
+			s.mu.Unlock()
+			return nil
+		}
+	}
+}
+
+// Function verifies certificate chain and key pair of LoadCertificate request.
+func (s *fakeCertServer) verifyLoad(load *cert.LoadCertificateRequest, key *rsa.PrivateKey) (*x509.Certificate, error) {
+	c, err := parseCertificate(load.GetCertificate().GetCertificate())
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	for _, ca := range load.GetCaCertificates() {
+		roots.AppendCertsFromPEM(ca.GetCertificate())
+	}
+	_, err = c.Verify(x509.VerifyOptions{Roots: roots})
+	if err != nil {
+		return nil, err
+	}
+	if load.GetKeyPair() != nil {
+		b, _ := pem.Decode(load.GetKeyPair().GetPrivateKey())
+		if b == nil {
+			return nil, fmt.Errorf("no private key")
+		}
+		key, err = x509.ParsePKCS1PrivateKey(b.Bytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if key == nil || !key.PublicKey.Equal(c.PublicKey) {
+		return nil, fmt.Errorf("certificate doesn't match the key")
+	}
+	return c, nil
+}
+
+// Starts fake gNOI CertificateManagement server and returns client connected to it.
+func newFakeClient(t *testing.T, srv cert.CertificateManagementServer) *Client {
+	t.Helper()
+	conn := sessiontest.NewConn(t, func(s *grpc.Server) { cert.RegisterCertificateManagementServer(s, srv) })
+	return &Client{t: sessiontest.NewTarget(), cc: cert.NewCertificateManagementClient(conn)}
+}
+
+func TestLoadCA(t *testing.T) {
+	ca, err := LoadCA(newTestCA(t, true))
+	if err != nil {
+		t.Fatalf("got an error from LoadCA(): %v", err)
+	}
+	if ca.Cert.Subject.CommonName != "lab root CA" || len(ca.PEM) == 0 {
+		t.Errorf("incorrect CA loaded: %+v", ca.Cert.Subject)
+	}
+
+	_, err = LoadCA(newTestCA(t, false))
+	if err == nil || !strings.Contains(err.Error(), "isn't CA") {
+		t.Errorf("expected error for non-CA certificate; got: %v", err)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	ca, err := LoadCA(newTestCA(t, true))
+	if err != nil {
+		t.Fatalf("got an error from LoadCA(): %v", err)
+	}
+
+	testData := []struct {
+		testName     string
+		localKey     bool
+		verifyErr    error
+		expFinalized bool
+		expDNSNames  []string
+	}{
+		{testName: "CSR generated by the target", expFinalized: true, expDNSNames: []string{"srl1", "srl1.lab"}},
+		{testName: "Local key pair", localKey: true, expFinalized: true, expDNSNames: []string{"srl1"}},
+		{testName: "Checking err: verification failed", verifyErr: errors.New("handshake failed"), expDNSNames: []string{"srl1", "srl1.lab"}},
+	}
+
+	for _, d := range testData {
+		t.Run(d.testName, func(t *testing.T) {
+			srv := new(fakeCertServer)
+			c := newFakeClient(t, srv)
+			opts := &Options{CertificateID: "clab-profile", CommonName: "srl1", DNSNames: []string{"srl1"}, LocalKey: d.localKey}
+			err := c.Rotate(ca, opts, func() error { return d.verifyErr })
+			if d.verifyErr != nil {
+				if !errors.Is(err, d.verifyErr) {
+					t.Fatalf("expected error: %v; got: %v", d.verifyErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("got an error from Rotate(): %v", err)
+			}
+
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			if srv.finalized != d.expFinalized {
+				t.Errorf("expected finalized: %v; got: %v", d.expFinalized, srv.finalized)
+			}
+			if srv.loaded == nil {
+				t.Fatalf("no certificate loaded")
+			}
+			if srv.loaded.Subject.CommonName != "srl1" || strings.Join(srv.loaded.DNSNames, ",") != strings.Join(d.expDNSNames, ",") {
+				t.Errorf("incorrect certificate loaded: %s %v", srv.loaded.Subject, srv.loaded.DNSNames)
+			}
+		})
+	}
+}
+
+func TestOptionsCheck(t *testing.T) {
+	if err := (&Options{CommonName: "srl1"}).check(); err == nil {
+		t.Errorf("expected error for missed certificate ID")
+	}
+	o := &Options{CertificateID: "clab-profile", CommonName: "srl1"}
+	if err := o.check(); err != nil {
+		t.Fatalf("got an error from check(): %v", err)
+	}
+	if o.KeySize != DefaultKeySize || o.Validity != DefaultValidity {
+		t.Errorf("defaults aren't populated: %+v", o)
+	}
+}
@@ -0,0 +1,66 @@
+package file
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session"
+	"github.com/openconfig/gnoi/file"
+	"github.com/openconfig/gnoi/types"
+)
+
+// Client executes gNOI File RPCs over the shared session, so several calls reuse the same connection.
+type Client struct {
+	t      *lib.SRLTarget
+	fc     file.FileClient
+	method types.HashType_HashMethod // hash method reported by the last Get, downloads are hashed with it only
+}
+
+// Creates File client over provided session.
+func NewClient(s *session.Session) *Client {
+	return &Client{t: s.Target(), fc: file.NewFileClient(s.Conn())}
+}
+
+// Streams remote file into w, see GetTo().
+func (c *Client) GetTo(rFile string, w io.Writer, progress ProgressFunc) (int64, error) {
+	n, h, err := get(c.t, c.fc, &file.GetRequest{RemoteFile: rFile}, c.method, w, progress)
+	if err == nil {
+		c.method = h.GetMethod()
+	}
+	return n, err
+}
+
+// Removes remote file.
+func (c *Client) Remove(rFile string) error {
+	return remove(c.t, c.fc, rFile)
+}
+
+// Streams content of r to the remote file, see PutReader().
+func (c *Client) PutReader(r io.Reader, rFile string, perm os.FileMode, progress ProgressFunc) (int64, error) {
+	details := &file.PutRequest_Details{
+		RemoteFile:  rFile,
+		Permissions: permToGNOI(perm),
+	}
+	return put(c.t, c.fc, r, details, progress)
+}
+
+// Returns stats of the remote path, see Stat().
+func (c *Client) Stat(path string) ([]StatInfo, error) {
+	return stat(c.t, c.fc, path)
+}
+
+// Returns stats of all files under remote path, see List().
+func (c *Client) List(path string) ([]StatInfo, error) {
+	return list(c.t, c.fc, path)
+}
+
+// Instructs the target to upload local file to the remote URL, see TransferToRemote().
+func (c *Client) TransferToRemote(lPath string, rURL string, expected HashFunc, timeout time.Duration) (*types.HashType, error) {
+	rd, err := remoteDownload(rURL)
+	if err != nil {
+		return nil, err
+	}
+	return transfer(c.fc, &file.TransferToRemoteRequest{LocalPath: lPath, RemoteDownload: rd}, expected, timeout)
+}
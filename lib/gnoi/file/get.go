@@ -3,252 +3,144 @@ package file
 import (
 	"bytes"
 	"context"
-	"crypto/md5"
-	"crypto/sha256"
-	"crypto/sha512"
 	"fmt"
-	"hash"
 	"io"
-	"io/ioutil"
 	"os"
-	"strings"
+	"time"
 
 	"github.com/azyablov/fat/lib"
-	"github.com/azyablov/gnmi-pg/gnmilib"
+	"github.com/azyablov/fat/lib/session"
 	"github.com/openconfig/gnoi/file"
 	"github.com/openconfig/gnoi/types"
-	"google.golang.org/grpc"
 )
 
+// ProgressFunc is called after every chunk received or sent with the total number of bytes transferred so far.
+type ProgressFunc func(n int64)
+
+// Downloads remote file into local one streaming it chunk by chunk, partially written local file is removed on failure.
 func GetFile(t *lib.SRLTarget, rFile *string, lFile *string) error {
-	r, err := GetReader(t, rFile)
-	if err != nil {
-		return err
-	}
+	return GetFileProgress(t, rFile, lFile, nil)
+}
 
+// Same as GetFile, but reports progress via provided callback, if not nil.
+func GetFileProgress(t *lib.SRLTarget, rFile *string, lFile *string, progress ProgressFunc) error {
 	// Opening local file for writing
-	f, err := os.Create(*lFile)
+	w, err := CreateWriter(*lFile)
 	if err != nil {
-		return fmt.Errorf("can't create file: %s", err)
+		return err
 	}
-	defer f.Close()
 
-	// pulling all data
-	data, err := ioutil.ReadAll(r)
+	_, err = GetTo(t, rFile, w, progress)
 	if err != nil {
-		return fmt.Errorf("can't read all from buffer: %s", err)
+		w.Abort()
+		return err
 	}
-	// writing to the file
-	_, err = f.Write(data)
+	err = w.Close()
 	if err != nil {
-		return fmt.Errorf("unable to write data in file: %s", err)
+		os.Remove(*lFile)
+		return err
 	}
 	return nil
 }
 
-func grpcSetup(t *lib.SRLTarget) (*grpc.ClientConn, error) {
-
-	// Setting up grpc options
-	var dOpts *[]grpc.DialOption
-	var err error
-	if *t.SkipVerify {
-		dOpts, err = gnmilib.SetupGNMISecureTransport(
-			gnmilib.TLSInit{
-				//			InsecConn:      *t.InsecConn,
-				SkipVerify:     *t.SkipVerify,
-				TargetHostname: *t.Hostname,
-				//			RootCA:         *t.RootCA,
-				//			Cert:           *t.Cert,
-				//			Key:            *t.Key,
-			})
-
-		if err != nil {
-			return nil, fmt.Errorf("setting up grpc options: %s", err)
-		}
-	} else {
-		dOpts, err = gnmilib.SetupGNMISecureTransport(
-			gnmilib.TLSInit{
-				InsecConn:      *t.InsecConn,
-				SkipVerify:     *t.SkipVerify,
-				TargetHostname: *t.Hostname,
-				RootCA:         *t.RootCA,
-				Cert:           *t.Cert,
-				Key:            *t.Key,
-			})
-
-		if err != nil {
-			return nil, fmt.Errorf("setting up grpc options: %s", err)
-		}
-	}
-	// Set up a connection to the server.
-	var host string // target address to connect using grpc.Dial()
-	if strings.Contains(*t.Hostname, ":") {
-		host = *t.Hostname
-	} else {
-		host = fmt.Sprintf("%s:%v", *t.Hostname, *t.PortgNOI)
-	}
-
-	// Dialing and getting gRPC connection.
-	gRPCconn, err := grpc.Dial(host, *dOpts...)
+// Downloads remote file and returns reader of its content buffered in memory.
+// Use GetTo() or GetFile() for large files.
+func GetReader(t *lib.SRLTarget, rFile *string) (io.Reader, error) {
+	fileBuf := new(bytes.Buffer)
+	fileBuf.Grow(65536)
+	_, err := GetTo(t, rFile, fileBuf, nil)
 	if err != nil {
-		return nil, fmt.Errorf("can't not connect to the host %s due to %s", *t.Hostname, err)
+		return nil, err
 	}
-
-	return gRPCconn, nil
+	return fileBuf, nil
 }
 
-func GetReader(t *lib.SRLTarget, rFile *string) (io.Reader, error) {
-
-	// Setting up grpc options
-	var dOpts *[]grpc.DialOption
-	var err error
-	if *t.SkipVerify {
-		dOpts, err = gnmilib.SetupGNMISecureTransport(
-			gnmilib.TLSInit{
-				//			InsecConn:      *t.InsecConn,
-				SkipVerify:     *t.SkipVerify,
-				TargetHostname: *t.Hostname,
-				//			RootCA:         *t.RootCA,
-				//			Cert:           *t.Cert,
-				//			Key:            *t.Key,
-			})
+// Streams remote file into w as chunks arrive, hashing them on the fly, and verifies the hash sent by the target at the end.
+// Since the hash method is reported at the end only, content is hashed with all methods gNOI supports,
+// Client hashes subsequent downloads with the method reported by the previous one only.
+// Target timeout is applied as inactivity timeout between chunks, rather than to the whole transfer.
+// Returns the number of bytes written.
+func GetTo(t *lib.SRLTarget, rFile *string, w io.Writer, progress ProgressFunc) (int64, error) {
+	var n int64
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		n, err = c.GetTo(*rFile, w, progress)
+		return err
+	})
+	return n, err
+}
 
+// Function executes Get request using provided client and streams received content into w.
+// Content is hashed with method, if it's specified, or with all supported ones otherwise.
+// Returns the number of bytes written and verified hash sent by the target.
+func get(t *lib.SRLTarget, fClient file.FileClient, fGetReq *file.GetRequest, method types.HashType_HashMethod, w io.Writer, progress ProgressFunc) (int64, *types.HashType, error) {
+	var (
+		written int64
+		mHash   *types.HashType
+	)
+	// Get is idempotent, but transfer can't be restarted once data is written into w.
+	err := t.Retry.Do(func(err error) bool { return written == 0 && session.Retryable(err) }, func() error {
+		// Creating context cancelled on inactivity.
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		idle := time.AfterFunc(*t.Timeout, cancel)
+		defer idle.Stop()
+
+		fGetStream, err := fClient.Get(ctx, fGetReq)
 		if err != nil {
-			return nil, fmt.Errorf("setting up grpc options: %s", err)
+			return fmt.Errorf("can't exec Get request: %w", err)
 		}
-	} else {
-		dOpts, err = gnmilib.SetupGNMISecureTransport(
-			gnmilib.TLSInit{
-				InsecConn:      *t.InsecConn,
-				SkipVerify:     *t.SkipVerify,
-				TargetHostname: *t.Hostname,
-				RootCA:         *t.RootCA,
-				Cert:           *t.Cert,
-				Key:            *t.Key,
-			})
 
-		if err != nil {
-			return nil, fmt.Errorf("setting up grpc options: %s", err)
+		// Stream handling, hashing contents on the fly.
+		h := newHasher(method)
+		mw := io.MultiWriter(w, h)
+		for {
+			getResp, err := fGetStream.Recv()
+			if err != nil {
+				return fmt.Errorf("can't get GetResponse: %w", err)
+			}
+			idle.Reset(*t.Timeout)
+			// file contents
+			bMessage := getResp.GetContents()
+			if bMessage != nil {
+				n, err := mw.Write(bMessage)
+				written += int64(n)
+				if err != nil {
+					return fmt.Errorf("unable to write data: %w", err)
+				}
+				if progress != nil {
+					progress(written)
+				}
+				continue
+			}
+			// or hash
+			mHash = getResp.GetHash()
+			return h.verify(mHash)
 		}
-	}
-	// Set up a connection to the server.
-	var host string // target address to connect using grpc.Dial()
-	if strings.Contains(*t.Hostname, ":") {
-		host = *t.Hostname
-	} else {
-		host = fmt.Sprintf("%s:%v", *t.Hostname, *t.PortgNOI)
-	}
-
-	// Dialing and getting gRPC connection.
-	gRPCconn, err := grpc.Dial(host, *dOpts...)
-	if err != nil {
-		return nil, fmt.Errorf("can't not connect to the host %s due to %s", *t.Hostname, err)
-	}
-	defer gRPCconn.Close()
-
-	// Creating context with timeout.
-	ctx, cancel := context.WithTimeout(context.Background(), *t.Timeout)
-	defer cancel()
-
-	// Attaching credentials to the context
-	uc := gnmilib.UserCredentials{
-		Username: *t.Username,
-		Password: *t.Password,
-	}
-	// Populating credential in provided context
-	ctx, err = gnmilib.PopulateMDCredentials(ctx, uc)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("can't populate context with credentials: %s", err)
+		return written, nil, err
 	}
-
-	// Constructing file Get request
-	fGetReq := new(file.GetRequest)
-	fGetReq.RemoteFile = *rFile
-	// Initializing buffer and hash var
-	fileBuf := new(bytes.Buffer)
-	fileBuf.Grow(65536)
-	var mHash *types.HashType
-
-	fClient := file.NewFileClient(gRPCconn)
-	fGetStream, err := fClient.Get(ctx, fGetReq)
-	if err != nil {
-		return nil, fmt.Errorf("can't exec Get request: %s", err)
-	}
-
-	// Stream handling.
-	for {
-		getResp, err := fGetStream.Recv()
-		if err != nil {
-			return nil, fmt.Errorf("can't get GetResponse: %s", err)
-		}
-		// file contents
-		bMessage := getResp.GetContents()
-		if bMessage != nil {
-			fileBuf.Write(bMessage)
-			continue
-		}
-		// or hash
-		mHash = getResp.GetHash()
-		break
-
-	}
-	// Checking hash.
-	var cHash hash.Hash
-	switch mHash.GetMethod() {
-	case types.HashType_MD5:
-		cHash = md5.New()
-	case types.HashType_SHA256:
-		cHash = sha256.New()
-	case types.HashType_SHA512:
-		cHash = sha512.New()
-	case types.HashType_UNSPECIFIED:
-		return nil, fmt.Errorf("don't know how to handle HashType_UNSPECIFIED: %s", err)
-	default:
-		return nil, fmt.Errorf("inappropriate specification hash method: %s", err)
-	}
-
-	hSum := cHash.Sum(fileBuf.Bytes())
-	if bytes.Equal(hSum, mHash.Hash) {
-		return nil, fmt.Errorf("hash sum received is wrong or error happened during transmission: %s", err)
-	}
-
-	return fileBuf, nil
-
+	return written, mHash, nil
 }
 
+// Removes remote file.
 func RemoveFile(t *lib.SRLTarget, rFile *string) error {
-	// setup gRPC
-	gRPCconn, err := grpcSetup(t)
-	if err != nil {
-		return err
-	}
-	defer gRPCconn.Close()
+	return session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		return NewClient(s).Remove(*rFile)
+	})
+}
 
+// Function executes Remove request using provided client.
+func remove(t *lib.SRLTarget, fClient file.FileClient, rFile string) error {
 	// Creating context with timeout.
 	ctx, cancel := context.WithTimeout(context.Background(), *t.Timeout)
 	defer cancel()
 
-	// Attaching credentials to the context
-	uc := gnmilib.UserCredentials{
-		Username: *t.Username,
-		Password: *t.Password,
-	}
-	// Populating credential in provided context
-	ctx, err = gnmilib.PopulateMDCredentials(ctx, uc)
-	if err != nil {
-		return fmt.Errorf("can't populate context with credentials: %s", err)
-	}
-
-	// Constructing file Remove request
-	fRemReq := new(file.RemoveRequest)
-	fRemReq.RemoteFile = *rFile
-
-	// exec request
-	fClient := file.NewFileClient(gRPCconn)
-	fRemResp, err := fClient.Remove(ctx, fRemReq)
+	_, err := fClient.Remove(ctx, &file.RemoveRequest{RemoteFile: rFile})
 	if err != nil {
-		return fmt.Errorf("can't exec Remove request: %s", err)
+		return fmt.Errorf("can't exec Remove request: %w", err)
 	}
-	fmt.Println(fRemResp.String())
 	return nil
 }
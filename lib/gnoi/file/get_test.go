@@ -0,0 +1,138 @@
+package file
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/azyablov/fat/lib/session/sessiontest"
+	"github.com/openconfig/gnoi/file"
+	"github.com/openconfig/gnoi/types"
+	"google.golang.org/grpc"
+)
+
+const testChunkSize = 4
+
+// fakeFileServer streams content in chunks followed by the hash message.
+type fakeFileServer struct {
+	file.UnimplementedFileServer
+	content []byte
+	hash    *types.HashType // nil means stream is closed w/o hash message.
+}
+
+func (s *fakeFileServer) Get(req *file.GetRequest, stream file.File_GetServer) error {
+	for i := 0; i < len(s.content); i += testChunkSize {
+		end := i + testChunkSize
+		if end > len(s.content) {
+			end = len(s.content)
+		}
+		if err := stream.Send(&file.GetResponse{Response: &file.GetResponse_Contents{Contents: s.content[i:end]}}); err != nil {
+			return err
+		}
+	}
+	if s.hash == nil {
+		return nil
+	}
+	return stream.Send(&file.GetResponse{Response: &file.GetResponse_Hash{Hash: s.hash}})
+}
+
+// Starts fake gNOI File server and returns client connected to it.
+func newFakeFileClient(t *testing.T, srv file.FileServer) file.FileClient {
+	t.Helper()
+	return file.NewFileClient(sessiontest.NewConn(t, func(s *grpc.Server) { file.RegisterFileServer(s, srv) }))
+}
+
+func TestGet(t *testing.T) {
+	content := []byte("system {\n    banner {\n    }\n}\n")
+	md5Sum := md5.Sum(content)
+	sha256Sum := sha256.Sum256(content)
+	sha512Sum := sha512.Sum512(content)
+
+	testData := []struct {
+		testName string
+		method   types.HashType_HashMethod
+		hash     *types.HashType
+		expErr   string
+	}{
+		{testName: "MD5", hash: &types.HashType{Method: types.HashType_MD5, Hash: md5Sum[:]}},
+		{testName: "SHA256", hash: &types.HashType{Method: types.HashType_SHA256, Hash: sha256Sum[:]}},
+		{testName: "SHA512", hash: &types.HashType{Method: types.HashType_SHA512, Hash: sha512Sum[:]}},
+		{testName: "SHA256 known in advance", method: types.HashType_SHA256, hash: &types.HashType{Method: types.HashType_SHA256, Hash: sha256Sum[:]}},
+		{testName: "Checking err: method differs from expected one", method: types.HashType_MD5, hash: &types.HashType{Method: types.HashType_SHA256, Hash: sha256Sum[:]}, expErr: "isn't hashed with SHA256"},
+		{testName: "Checking err: checksum mismatch", hash: &types.HashType{Method: types.HashType_SHA256, Hash: md5Sum[:]}, expErr: "checksum mismatch"},
+		{testName: "Checking err: unspecified hash method", hash: &types.HashType{Method: types.HashType_UNSPECIFIED}, expErr: "HashType_UNSPECIFIED"},
+		{testName: "Checking err: no hash message", hash: nil, expErr: "can't get GetResponse"},
+	}
+
+	for _, d := range testData {
+		t.Run(d.testName, func(t *testing.T) {
+			fc := newFakeFileClient(t, &fakeFileServer{content: content, hash: d.hash})
+			buf := new(bytes.Buffer)
+			var progress []int64
+			n, h, err := get(sessiontest.NewTarget(), fc, &file.GetRequest{RemoteFile: "/tmp/myconfig.cfg"}, d.method, buf, func(n int64) {
+				progress = append(progress, n)
+			})
+			t.Log(err)
+
+			if d.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), d.expErr) {
+					t.Fatalf("expected error: %s; got: %v", d.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got an error from get(): %v", err)
+			}
+			if n != int64(len(content)) || !bytes.Equal(buf.Bytes(), content) {
+				t.Errorf("incorrect content received: %q", buf.Bytes())
+			}
+			if len(progress) == 0 || progress[len(progress)-1] != int64(len(content)) {
+				t.Errorf("incorrect progress reported: %v", progress)
+			}
+			if h.GetMethod() != d.hash.GetMethod() || !bytes.Equal(h.GetHash(), d.hash.GetHash()) {
+				t.Errorf("incorrect hash returned: %+v", h)
+			}
+		})
+	}
+}
+
+func TestChecksumMismatchError(t *testing.T) {
+	content := []byte("content")
+	fc := newFakeFileClient(t, &fakeFileServer{content: content, hash: &types.HashType{Method: types.HashType_MD5, Hash: []byte("wrong")}})
+	_, _, err := get(sessiontest.NewTarget(), fc, &file.GetRequest{RemoteFile: "/tmp/myconfig.cfg"}, types.HashType_UNSPECIFIED, new(bytes.Buffer), nil)
+
+	var mErr *ChecksumMismatchError
+	if !errors.As(err, &mErr) {
+		t.Fatalf("expected *ChecksumMismatchError; got: %v", err)
+	}
+	sum := md5.Sum(content)
+	if mErr.Method != types.HashType_MD5 || !bytes.Equal(mErr.Actual, sum[:]) || !bytes.Equal(mErr.Expected, []byte("wrong")) {
+		t.Errorf("incorrect error details: %+v", mErr)
+	}
+}
+
+func TestClientHashMethod(t *testing.T) {
+	content := []byte("content")
+	md5Sum := md5.Sum(content)
+	sha256Sum := sha256.Sum256(content)
+	srv := &fakeFileServer{content: content, hash: &types.HashType{Method: types.HashType_SHA256, Hash: sha256Sum[:]}}
+	c := &Client{t: sessiontest.NewTarget(), fc: newFakeFileClient(t, srv)}
+
+	if _, err := c.GetTo("/tmp/myconfig.cfg", io.Discard, nil); err != nil {
+		t.Fatalf("got an error from GetTo(): %v", err)
+	}
+	if c.method != types.HashType_SHA256 {
+		t.Errorf("expected method reported by the target to be kept; got: %s", c.method)
+	}
+
+	// Subsequent downloads are hashed with SHA256 only.
+	srv.hash = &types.HashType{Method: types.HashType_MD5, Hash: md5Sum[:]}
+	if _, err := c.GetTo("/tmp/myconfig.cfg", io.Discard, nil); err == nil {
+		t.Errorf("expected an error for hash method changed by the target")
+	}
+}
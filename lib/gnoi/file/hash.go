@@ -0,0 +1,96 @@
+package file
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/openconfig/gnoi/types"
+)
+
+// ChecksumMismatchError is returned when hash sum calculated over the received content
+// doesn't match the one sent by the target.
+type ChecksumMismatchError struct {
+	Method   types.HashType_HashMethod
+	Expected []byte // Hash sum sent by the target.
+	Actual   []byte // Hash sum calculated over received content.
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%s checksum mismatch: expected %x, got %x", e.Method, e.Expected, e.Actual)
+}
+
+// HashFunc returns hash sum of the content calculated in the method, e.g. reported by the target.
+type HashFunc func(method types.HashType_HashMethod) ([]byte, error)
+
+// Returns HashFunc of the content.
+func BytesHash(b []byte) HashFunc {
+	return func(method types.HashType_HashMethod) ([]byte, error) {
+		newHash, ok := hashFuncs[method]
+		if !ok {
+			return nil, fmt.Errorf("inappropriate specification hash method: %s", method)
+		}
+		h := newHash()
+		h.Write(b)
+		return h.Sum(nil), nil
+	}
+}
+
+// Hash constructors of the methods supported by gNOI.
+var hashFuncs = map[types.HashType_HashMethod]func() hash.Hash{
+	types.HashType_MD5:    md5.New,
+	types.HashType_SHA256: sha256.New,
+	types.HashType_SHA512: sha512.New,
+}
+
+// hasher calculates hash sums of the content in one pass.
+type hasher struct {
+	io.Writer
+	sums map[types.HashType_HashMethod]hash.Hash
+}
+
+// Creates hasher of the method, if it's known in advance. Otherwise, e.g. Get reports the method
+// only in the last message of the stream, content is hashed with all methods supported by gNOI,
+// which takes roughly three times more CPU.
+func newHasher(method types.HashType_HashMethod) *hasher {
+	h := &hasher{sums: make(map[types.HashType_HashMethod]hash.Hash)}
+	if newHash, ok := hashFuncs[method]; ok {
+		h.sums[method] = newHash()
+	} else {
+		for m, newHash := range hashFuncs {
+			h.sums[m] = newHash()
+		}
+	}
+	ws := make([]io.Writer, 0, len(h.sums))
+	for _, s := range h.sums {
+		ws = append(ws, s)
+	}
+	h.Writer = io.MultiWriter(ws...)
+	return h
+}
+
+// Function compares the sum calculated over the content with the one received from the target.
+func (h *hasher) verify(mHash *types.HashType) error {
+	if mHash == nil {
+		return fmt.Errorf("no hash received from the target")
+	}
+	if mHash.GetMethod() == types.HashType_UNSPECIFIED {
+		return fmt.Errorf("don't know how to handle HashType_UNSPECIFIED")
+	}
+	if _, ok := hashFuncs[mHash.GetMethod()]; !ok {
+		return fmt.Errorf("inappropriate specification hash method: %s", mHash.GetMethod())
+	}
+	cHash, ok := h.sums[mHash.GetMethod()]
+	if !ok {
+		return fmt.Errorf("content isn't hashed with %s, target reported another method before", mHash.GetMethod())
+	}
+	sum := cHash.Sum(nil)
+	if !bytes.Equal(sum, mHash.GetHash()) {
+		return &ChecksumMismatchError{Method: mHash.GetMethod(), Expected: mHash.GetHash(), Actual: sum}
+	}
+	return nil
+}
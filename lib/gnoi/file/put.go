@@ -0,0 +1,123 @@
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session"
+	"github.com/openconfig/gnoi/file"
+	"github.com/openconfig/gnoi/types"
+)
+
+// Size of the chunk used to stream file contents toward the target.
+const PutChunkSize = 64 * 1024
+
+// Uploads local file to the target setting provided permissions on the remote one.
+func PutFile(t *lib.SRLTarget, lFile *string, rFile *string, perm os.FileMode) error {
+	f, err := os.Open(*lFile)
+	if err != nil {
+		return fmt.Errorf("can't open file: %s", err)
+	}
+	defer f.Close()
+
+	_, err = PutReader(t, f, rFile, perm, nil)
+	return err
+}
+
+// Streams content of r to the remote file in chunks followed by SHA256 hash message.
+// Target timeout is applied as inactivity timeout between chunks. Returns the number of bytes sent.
+func PutReader(t *lib.SRLTarget, r io.Reader, rFile *string, perm os.FileMode, progress ProgressFunc) (int64, error) {
+	var n int64
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		n, err = c.PutReader(r, *rFile, perm, progress)
+		return err
+	})
+	return n, err
+}
+
+// Function executes Put request using provided client and streams content of r.
+func put(t *lib.SRLTarget, fClient file.FileClient, r io.Reader, details *file.PutRequest_Details, progress ProgressFunc) (int64, error) {
+	// Creating context cancelled on inactivity.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idle := time.AfterFunc(*t.Timeout, cancel)
+	defer idle.Stop()
+
+	// Content of r can't be replayed, so only stream opening is retried.
+	var fPutStream file.File_PutClient
+	err := t.Retry.Do(session.Retryable, func() error {
+		var err error
+		fPutStream, err = fClient.Put(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("can't exec Put request: %w", err)
+	}
+
+	// Stream handling, open message, contents and hash.
+	err = fPutStream.Send(&file.PutRequest{Request: &file.PutRequest_Open{Open: details}})
+	if err != nil {
+		return 0, putStreamErr(fPutStream, err)
+	}
+
+	var sent int64
+	h := sha256.New()
+	buf := make([]byte, PutChunkSize)
+	for {
+		n, rErr := io.ReadFull(r, buf)
+		if n > 0 {
+			h.Write(buf[:n])
+			err = fPutStream.Send(&file.PutRequest{Request: &file.PutRequest_Contents{Contents: append([]byte(nil), buf[:n]...)}})
+			if err != nil {
+				return sent, putStreamErr(fPutStream, err)
+			}
+			idle.Reset(*t.Timeout)
+			sent += int64(n)
+			if progress != nil {
+				progress(sent)
+			}
+		}
+		if rErr == io.EOF || rErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rErr != nil {
+			return sent, fmt.Errorf("can't read data: %w", rErr)
+		}
+	}
+
+	err = fPutStream.Send(&file.PutRequest{Request: &file.PutRequest_Hash{Hash: &types.HashType{
+		Method: types.HashType_SHA256,
+		Hash:   h.Sum(nil),
+	}}})
+	if err != nil {
+		return sent, putStreamErr(fPutStream, err)
+	}
+	_, err = fPutStream.CloseAndRecv()
+	if err != nil {
+		return sent, fmt.Errorf("can't get PutResponse: %w", err)
+	}
+	return sent, nil
+}
+
+// Function returns the actual error of the stream, since Send() returns io.EOF, if the stream was aborted by the target.
+func putStreamErr(s file.File_PutClient, err error) error {
+	if err == io.EOF {
+		if _, rErr := s.CloseAndRecv(); rErr != nil {
+			err = rErr
+		}
+	}
+	return fmt.Errorf("can't send PutRequest: %w", err)
+}
+
+// Function converts file mode into gNOI permissions, which are octal digits represented as decimal, e.g. 0644 -> 644.
+func permToGNOI(perm os.FileMode) uint32 {
+	p := uint32(perm.Perm())
+	return (p>>6&7)*100 + (p>>3&7)*10 + p&7
+}
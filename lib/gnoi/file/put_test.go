@@ -0,0 +1,75 @@
+package file
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/azyablov/fat/lib/session/sessiontest"
+	"github.com/openconfig/gnoi/file"
+)
+
+// fakePutServer records the uploaded file.
+type fakePutServer struct {
+	file.UnimplementedFileServer
+	details *file.PutRequest_Details
+	content bytes.Buffer
+	hash    []byte
+}
+
+func (s *fakePutServer) Put(stream file.File_PutServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&file.PutResponse{})
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case req.GetOpen() != nil:
+			s.details = req.GetOpen()
+		case req.GetContents() != nil:
+			s.content.Write(req.GetContents())
+		case req.GetHash() != nil:
+			s.hash = req.GetHash().GetHash()
+		}
+	}
+}
+
+func TestPut(t *testing.T) {
+	content := bytes.Repeat([]byte("set / system banner login-banner test\n"), PutChunkSize/16)
+	srv := new(fakePutServer)
+	fc := newFakeFileClient(t, srv)
+
+	var progress []int64
+	n, err := put(sessiontest.NewTarget(), fc, bytes.NewReader(content), &file.PutRequest_Details{RemoteFile: "/etc/opt/srlinux/config.cfg", Permissions: permToGNOI(0644)}, func(n int64) {
+		progress = append(progress, n)
+	})
+	if err != nil {
+		t.Fatalf("got an error from put(): %v", err)
+	}
+	if n != int64(len(content)) || !bytes.Equal(srv.content.Bytes(), content) {
+		t.Errorf("incorrect content uploaded: %d bytes", srv.content.Len())
+	}
+	if srv.details.GetRemoteFile() != "/etc/opt/srlinux/config.cfg" || srv.details.GetPermissions() != 644 {
+		t.Errorf("incorrect open details: %+v", srv.details)
+	}
+	sum := sha256.Sum256(content)
+	if !bytes.Equal(srv.hash, sum[:]) {
+		t.Errorf("incorrect hash: %x", srv.hash)
+	}
+	if len(progress) != 3 || progress[len(progress)-1] != int64(len(content)) {
+		t.Errorf("incorrect progress reported: %v", progress)
+	}
+}
+
+func TestPermToGNOI(t *testing.T) {
+	for perm, exp := range map[os.FileMode]uint32{0644: 644, 0755: 755, 0600: 600, os.ModeDir | 0750: 750} {
+		if p := permToGNOI(perm); p != exp {
+			t.Errorf("incorrect permissions for %v: expected %d; got: %d", perm, exp, p)
+		}
+	}
+}
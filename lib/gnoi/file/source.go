@@ -0,0 +1,124 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session"
+)
+
+// Name of the transport config source is known under, it isn't registered, since it depends on CLI source,
+// see NewConfigSourceFunc().
+const SourceName = "gnoi"
+
+// ConfigSource saves info config into the file on the target via CLI source and downloads it via gNOI File.Get.
+type ConfigSource struct {
+	s     *session.Session
+	c     *Client
+	cli   lib.ConfigSource // source implementing lib.CommandRunner
+	rFile string
+	saved bool        // config is saved into rFile
+	stop  func() bool // stops closing the session, once ctx is done
+}
+
+// Returns function opening config source over new gNOI session, config is saved into temporary file on the target
+// by the first of CLI sources available, e.g. jrpc.NewConfigSource or ssh.NewConfigSource, to be used via lib.Sources.
+// The next CLI source is tried only if the previous one is unavailable, see lib.IsUnavailable().
+func NewConfigSourceFunc(clis ...lib.ConfigSourceFunc) lib.ConfigSourceFunc {
+	return func(ctx context.Context, t *lib.SRLTarget) (lib.ConfigSource, error) {
+		if len(clis) == 0 {
+			return nil, fmt.Errorf("no CLI source to save config")
+		}
+		var errs []string
+		for _, open := range clis {
+			cli, err := open(ctx, t)
+			if err == nil {
+				// Availability of stateless transports is known after the first command only.
+				_, err = cli.ShowVersion()
+				if err == nil {
+					return NewConfigSourceVia(ctx, t, cli, fmt.Sprintf("/tmp/config-%d.cfg", time.Now().UnixNano()))
+				}
+				cli.Cleanup()
+			}
+			if !lib.IsUnavailable(err) {
+				return nil, err
+			}
+			errs = append(errs, err.Error())
+		}
+		return nil, &lib.UnavailableError{Err: fmt.Errorf("no CLI source available to save config: %s", strings.Join(errs, "; "))}
+	}
+}
+
+// Creates config source over new gNOI session, config is saved into rFile on the target by cli source.
+// The source takes ownership of cli and cleans it up. Download in flight is aborted by closing the session, once ctx is done.
+func NewConfigSourceVia(ctx context.Context, t *lib.SRLTarget, cli lib.ConfigSource, rFile string) (lib.ConfigSource, error) {
+	if _, ok := cli.(lib.CommandRunner); !ok {
+		cli.Cleanup()
+		return nil, fmt.Errorf("config source %T can't run CLI commands", cli)
+	}
+	s, err := session.New(t)
+	if err != nil {
+		cli.Cleanup()
+		return nil, err
+	}
+	return &ConfigSource{s: s, c: NewClient(s), cli: cli, rFile: rFile, stop: lib.AfterFunc(ctx, func() { s.Close() })}, nil
+}
+
+// Returns version reported by CLI source.
+func (s *ConfigSource) ShowVersion() (*lib.SystemVersion, error) {
+	return s.cli.ShowVersion()
+}
+
+// Saves running config into the file on the target and downloads it.
+func (s *ConfigSource) Info() (string, error) {
+	out, err := s.cli.(lib.CommandRunner).RunCLI("info | as text > " + s.rFile)
+	if err != nil {
+		return "", err
+	}
+	if len(out) != 0 {
+		if strings.Contains(out, "Permission denied") {
+			return "", &lib.UnavailableError{Err: fmt.Errorf("permission denied to save config into %s, file could be created via another transport previously: %s", s.rFile, out)}
+		}
+		return "", fmt.Errorf("expect no output while saving config, but got: %s", out)
+	}
+	s.saved = true
+	var b bytes.Buffer
+	_, err = s.c.GetTo(s.rFile, &b, nil)
+	if err != nil {
+		return "", session.SourceError(err)
+	}
+	return b.String(), nil
+}
+
+// Returns lib.ConfigFormatInfo.
+func (s *ConfigSource) Format() string {
+	return lib.ConfigFormatInfo
+}
+
+// Removes saved file, via CLI if gNOI isn't permitted to, e.g. file is created via JSON-RPC, and closes both sources.
+func (s *ConfigSource) Cleanup() error {
+	closed := !s.stop()
+	var err error
+	if s.saved {
+		err = s.c.Remove(s.rFile)
+		if err != nil {
+			_, err = s.cli.(lib.CommandRunner).RunCLI("file rm " + s.rFile)
+		}
+	}
+	cErr := s.cli.Cleanup()
+	if err == nil {
+		err = cErr
+	}
+	if closed {
+		return err
+	}
+	sErr := s.s.Close()
+	if err == nil {
+		err = sErr
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session/sessiontest"
+	"github.com/openconfig/gnoi/types"
+)
+
+// fakeCLISource records CLI commands and saves nothing.
+type fakeCLISource struct {
+	cmds []string
+	out  string
+}
+
+func (s *fakeCLISource) ShowVersion() (*lib.SystemVersion, error) {
+	return &lib.SystemVersion{Hostname: "leaf1", SoftwareVersion: "v23.3.1"}, nil
+}
+
+func (s *fakeCLISource) Info() (string, error) {
+	return "", fmt.Errorf("not implemented")
+}
+
+func (s *fakeCLISource) Format() string {
+	return lib.ConfigFormatInfo
+}
+
+func (s *fakeCLISource) Cleanup() error {
+	return nil
+}
+
+func (s *fakeCLISource) RunCLI(cmd string) (string, error) {
+	s.cmds = append(s.cmds, cmd)
+	return s.out, nil
+}
+
+func TestConfigSource(t *testing.T) {
+	content := []byte("system {\n    banner {\n    }\n}\n")
+	sum := sha256.Sum256(content)
+	fc := newFakeFileClient(t, &fakeFileServer{content: content, hash: &types.HashType{Method: types.HashType_SHA256, Hash: sum[:]}})
+
+	cli := &fakeCLISource{}
+	src := &ConfigSource{c: &Client{t: sessiontest.NewTarget(), fc: fc}, cli: cli, rFile: "/tmp/config.cfg"}
+	cfg, err := src.Info()
+	if err != nil {
+		t.Fatalf("got an error from Info(): %v", err)
+	}
+	if cfg != string(content) {
+		t.Errorf("incorrect config received: %q", cfg)
+	}
+	if len(cli.cmds) != 1 || cli.cmds[0] != "info | as text > /tmp/config.cfg" {
+		t.Errorf("unexpected CLI commands: %v", cli.cmds)
+	}
+
+	cli.out = "Error: permission denied"
+	if _, err := src.Info(); err == nil {
+		t.Errorf("expected an error for CLI output")
+	}
+}
+
+func TestNewConfigSourceFunc(t *testing.T) {
+	down := func(ctx context.Context, t *lib.SRLTarget) (lib.ConfigSource, error) {
+		return nil, &lib.UnavailableError{Err: fmt.Errorf("connection refused")}
+	}
+	broken := func(ctx context.Context, t *lib.SRLTarget) (lib.ConfigSource, error) {
+		return nil, fmt.Errorf("invalid response")
+	}
+	if _, err := NewConfigSourceFunc()(context.Background(), sessiontest.NewTarget()); err == nil {
+		t.Errorf("expected an error w/o CLI sources")
+	}
+	_, err := NewConfigSourceFunc(down, down)(context.Background(), sessiontest.NewTarget())
+	if err == nil || err.Error() != "no CLI source available to save config: connection refused; connection refused" {
+		t.Errorf("expected errors of all CLI sources; got: %v", err)
+	}
+	if !lib.IsUnavailable(err) {
+		t.Errorf("expected unavailable error w/o CLI sources available; got: %v", err)
+	}
+	_, err = NewConfigSourceFunc(broken, down)(context.Background(), sessiontest.NewTarget())
+	if err == nil || err.Error() != "invalid response" {
+		t.Errorf("expected error of the first CLI source only; got: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session"
+	"github.com/openconfig/gnoi/file"
+)
+
+// StatInfo describes remote file or directory.
+type StatInfo struct {
+	Path        string
+	Size        uint64
+	MTime       time.Time
+	Permissions os.FileMode
+	Umask       os.FileMode
+}
+
+// Returns stats of the remote path: the file itself or the entries of the directory.
+func Stat(t *lib.SRLTarget, path string) ([]StatInfo, error) {
+	var stats []StatInfo
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		stats, err = c.Stat(path)
+		return err
+	})
+	return stats, err
+}
+
+// Returns stats of all files under remote path walking directories recursively.
+// If path is a file, its own stats are returned.
+func List(t *lib.SRLTarget, path string) ([]StatInfo, error) {
+	var stats []StatInfo
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		stats, err = c.List(path)
+		return err
+	})
+	return stats, err
+}
+
+// Function walks remote path recursively.
+// gNOI doesn't mark directories explicitly, so entry is considered as directory,
+// if its stats contain anything but the entry itself.
+func list(t *lib.SRLTarget, fClient file.FileClient, path string) ([]StatInfo, error) {
+	stats, err := stat(t, fClient, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 1 && stats[0].Path == path {
+		// It's a file.
+		return stats, nil
+	}
+
+	var files []StatInfo
+	for _, s := range stats {
+		if s.Path == path {
+			continue
+		}
+		sub, err := list(t, fClient, s.Path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, sub...)
+	}
+	return files, nil
+}
+
+// Function executes Stat request using provided client.
+func stat(t *lib.SRLTarget, fClient file.FileClient, path string) ([]StatInfo, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("path can't be null string")
+	}
+	// Normalizing path, so it can be compared with the paths returned by the target.
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+
+	var fStatResp *file.StatResponse
+	// Stat is idempotent, so it's retried, if policy is attached to the target.
+	err := t.Retry.Do(session.Retryable, func() error {
+		// Creating context with timeout.
+		ctx, cancel := context.WithTimeout(context.Background(), *t.Timeout)
+		defer cancel()
+
+		var err error
+		fStatResp, err = fClient.Stat(ctx, &file.StatRequest{Path: path})
+		if err != nil {
+			return fmt.Errorf("can't exec Stat request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]StatInfo, 0, len(fStatResp.GetStats()))
+	for _, s := range fStatResp.GetStats() {
+		stats = append(stats, StatInfo{
+			Path:        s.GetPath(),
+			Size:        s.GetSize(),
+			MTime:       time.Unix(0, int64(s.GetLastModified())),
+			Permissions: permFromGNOI(s.GetPermissions()),
+			Umask:       permFromGNOI(s.GetUmask()),
+		})
+	}
+	return stats, nil
+}
+
+// Function converts gNOI permissions, which are octal digits represented as decimal, into file mode, e.g. 644 -> 0644.
+func permFromGNOI(p uint32) os.FileMode {
+	return os.FileMode((p/100%10)<<6 | (p/10%10)<<3 | p%10)
+}
@@ -0,0 +1,121 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib/session/sessiontest"
+	"github.com/openconfig/gnoi/file"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStatServer serves Stat requests from in-memory tree, directories are the keys w/o size.
+type fakeStatServer struct {
+	file.UnimplementedFileServer
+	files map[string]uint64
+	dirs  map[string]bool
+}
+
+func (s *fakeStatServer) Stat(ctx context.Context, req *file.StatRequest) (*file.StatResponse, error) {
+	resp := new(file.StatResponse)
+	newInfo := func(p string, size uint64) *file.StatInfo {
+		return &file.StatInfo{Path: p, Size: size, LastModified: 1_700_000_000_000_000_000, Permissions: 644, Umask: 22}
+	}
+	if size, ok := s.files[req.GetPath()]; ok {
+		resp.Stats = append(resp.Stats, newInfo(req.GetPath(), size))
+		return resp, nil
+	}
+	if !s.dirs[req.GetPath()] {
+		return nil, status.Errorf(codes.NotFound, "%s not found", req.GetPath())
+	}
+	for p, size := range s.files {
+		if path.Dir(p) == req.GetPath() {
+			resp.Stats = append(resp.Stats, newInfo(p, size))
+		}
+	}
+	for p := range s.dirs {
+		if p != req.GetPath() && path.Dir(p) == req.GetPath() {
+			resp.Stats = append(resp.Stats, newInfo(p, 4096))
+		}
+	}
+	return resp, nil
+}
+
+func newFakeStatServer() *fakeStatServer {
+	return &fakeStatServer{
+		files: map[string]uint64{
+			"/etc/opt/srlinux/checkpoint/checkpoint-0.json":      1024,
+			"/etc/opt/srlinux/checkpoint/checkpoint-1.json":      2048,
+			"/etc/opt/srlinux/checkpoint/archive/checkpoint.old": 512,
+		},
+		dirs: map[string]bool{
+			"/etc/opt/srlinux/checkpoint":         true,
+			"/etc/opt/srlinux/checkpoint/archive": true,
+			"/etc/opt/srlinux/checkpoint/empty":   true,
+		},
+	}
+}
+
+func TestStat(t *testing.T) {
+	fc := newFakeFileClient(t, newFakeStatServer())
+
+	stats, err := stat(sessiontest.NewTarget(), fc, "/etc/opt/srlinux/checkpoint/checkpoint-0.json")
+	if err != nil {
+		t.Fatalf("got an error from stat(): %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("expected single entry; got: %+v", stats)
+	}
+	exp := StatInfo{
+		Path:        "/etc/opt/srlinux/checkpoint/checkpoint-0.json",
+		Size:        1024,
+		MTime:       time.Unix(1_700_000_000, 0),
+		Permissions: 0644,
+		Umask:       0022,
+	}
+	if stats[0].Path != exp.Path || stats[0].Size != exp.Size || !stats[0].MTime.Equal(exp.MTime) ||
+		stats[0].Permissions != exp.Permissions || stats[0].Umask != exp.Umask {
+		t.Errorf("expected: %+v; got: %+v", exp, stats[0])
+	}
+
+	_, err = stat(sessiontest.NewTarget(), fc, "/tmp/nonexistent.cfg")
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error; got: %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	fc := newFakeFileClient(t, newFakeStatServer())
+
+	files, err := list(sessiontest.NewTarget(), fc, "/etc/opt/srlinux/checkpoint/")
+	if err != nil {
+		t.Fatalf("got an error from list(): %v", err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Path)
+	}
+	sort.Strings(got)
+	exp := []string{
+		"/etc/opt/srlinux/checkpoint/archive/checkpoint.old",
+		"/etc/opt/srlinux/checkpoint/checkpoint-0.json",
+		"/etc/opt/srlinux/checkpoint/checkpoint-1.json",
+	}
+	if strings.Join(got, ",") != strings.Join(exp, ",") {
+		t.Errorf("expected: %v; got: %v", exp, got)
+	}
+}
+
+func TestPermFromGNOI(t *testing.T) {
+	for _, p := range []os.FileMode{0644, 0755, 0600, 0022, 0} {
+		if got := permFromGNOI(permToGNOI(p)); got != p {
+			t.Errorf("expected: %v; got: %v", p, got)
+		}
+	}
+}
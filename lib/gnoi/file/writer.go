@@ -0,0 +1,79 @@
+package file
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openconfig/gnoi/types"
+)
+
+// Writer writes streamed content into local file, optionally hashing it on the fly, so the hash sent by the target
+// could be verified. Partially written file is removed, if writing is aborted.
+type Writer struct {
+	f    *os.File
+	h    *hasher
+	name string
+	n    int64
+}
+
+// Creates local file for writing w/o hashing, verifying the content is up to the caller, e.g. GetTo() does it itself.
+func CreateWriter(lFile string) (*Writer, error) {
+	f, err := os.Create(lFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't create file: %s", err)
+	}
+	return &Writer{f: f, name: lFile}, nil
+}
+
+// Same as CreateWriter(), but written content is hashed with the method to be checked by Verify().
+func CreateHashWriter(lFile string, method types.HashType_HashMethod) (*Writer, error) {
+	w, err := CreateWriter(lFile)
+	if err != nil {
+		return nil, err
+	}
+	w.h = newHasher(method)
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	n, err := w.f.Write(p)
+	if w.h != nil {
+		w.h.Write(p[:n])
+	}
+	w.n += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("unable to write data in file: %s", err)
+	}
+	return n, nil
+}
+
+// Returns the number of bytes written so far.
+func (w *Writer) Written() int64 {
+	return w.n
+}
+
+// Compares the hash of written content with the one received from the target.
+func (w *Writer) Verify(mHash *types.HashType) error {
+	if w.h == nil {
+		return fmt.Errorf("content of %s isn't hashed", w.name)
+	}
+	return w.h.verify(mHash)
+}
+
+// Flushes and closes the file.
+func (w *Writer) Close() error {
+	err := w.f.Sync()
+	if cErr := w.f.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write data in file: %s", err)
+	}
+	return nil
+}
+
+// Closes and removes partially written file.
+func (w *Writer) Abort() {
+	w.f.Close()
+	os.Remove(w.name)
+}
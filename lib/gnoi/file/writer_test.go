@@ -0,0 +1,62 @@
+package file
+
+import (
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openconfig/gnoi/types"
+)
+
+func TestWriter(t *testing.T) {
+	content := []byte("system {\n}\n")
+	sum := sha256.Sum256(content)
+	lFile := filepath.Join(t.TempDir(), "artifact.tgz")
+
+	w, err := CreateHashWriter(lFile, types.HashType_SHA256)
+	if err != nil {
+		t.Fatalf("got an error from CreateHashWriter(): %v", err)
+	}
+	if _, err := w.Write(content); err != nil {
+		t.Fatalf("got an error from Write(): %v", err)
+	}
+	if w.Written() != int64(len(content)) {
+		t.Errorf("expected %d bytes written; got: %d", len(content), w.Written())
+	}
+	if err := w.Verify(&types.HashType{Method: types.HashType_SHA256, Hash: sum[:]}); err != nil {
+		t.Errorf("got an error from Verify(): %v", err)
+	}
+	var mErr *ChecksumMismatchError
+	if err := w.Verify(&types.HashType{Method: types.HashType_SHA256, Hash: []byte("wrong")}); !errors.As(err, &mErr) {
+		t.Errorf("expected *ChecksumMismatchError; got: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("got an error from Close(): %v", err)
+	}
+	b, err := os.ReadFile(lFile)
+	if err != nil || string(b) != string(content) {
+		t.Errorf("incorrect file content: %q, %v", b, err)
+	}
+
+	w, err = CreateHashWriter(lFile, types.HashType_SHA256)
+	if err != nil {
+		t.Fatalf("got an error from CreateHashWriter(): %v", err)
+	}
+	w.Write(content)
+	w.Abort()
+	if _, err := os.Stat(lFile); !os.IsNotExist(err) {
+		t.Errorf("expected file to be removed on abort; got: %v", err)
+	}
+
+	w, err = CreateWriter(lFile)
+	if err != nil {
+		t.Fatalf("got an error from CreateWriter(): %v", err)
+	}
+	defer w.Abort()
+	w.Write(content)
+	if err := w.Verify(&types.HashType{Method: types.HashType_SHA256, Hash: sum[:]}); err == nil {
+		t.Errorf("expected an error from Verify() of writer w/o hashing")
+	}
+}
@@ -0,0 +1,27 @@
+module github.com/azyablov/fat/lib/gnoi/healthz
+
+go 1.18
+
+require (
+	github.com/azyablov/fat/lib v0.0.0-20230317114747-14ecb7a2410e
+	github.com/azyablov/fat/lib/gnoi/file v0.0.0-20230317114747-14ecb7a2410e
+	github.com/azyablov/fat/lib/session v0.0.0-20230317114747-14ecb7a2410e
+	github.com/openconfig/gnoi v0.0.0-20230221223856-1727ed932554
+	google.golang.org/grpc v1.53.0
+	google.golang.org/protobuf v1.28.1
+)
+
+require (
+	github.com/azyablov/gnmi-pg/gnmilib v0.0.0-20230307170529-c2aceddccaa0 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	golang.org/x/net v0.6.0 // indirect
+	golang.org/x/sys v0.5.0 // indirect
+	golang.org/x/text v0.7.0 // indirect
+	google.golang.org/genproto v0.0.0-20230110181048-76db0878b65f // indirect
+)
+
+replace (
+	github.com/azyablov/fat/lib => ../../
+	github.com/azyablov/fat/lib/gnoi/file => ../file
+	github.com/azyablov/fat/lib/session => ../../session
+)
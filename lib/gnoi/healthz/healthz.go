@@ -0,0 +1,333 @@
+// Package healthz wraps gNOI Healthz service RPCs to collect component health status and debug artifacts.
+package healthz
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/gnoi/file"
+	"github.com/azyablov/fat/lib/session"
+	"github.com/openconfig/gnoi/healthz"
+	"github.com/openconfig/gnoi/types"
+)
+
+// Artifact types.
+const (
+	ArtifactFile   = "file"
+	ArtifactProto  = "proto"
+	ArtifactCustom = "custom"
+)
+
+// ComponentStatus is the health status of the component reported by the target.
+type ComponentStatus struct {
+	Component     string             `json:"component"`
+	Path          string             `json:"path"`
+	ID            string             `json:"id,omitempty"`
+	Status        string             `json:"status"`
+	Healthy       bool               `json:"healthy"`
+	Acknowledged  bool               `json:"acknowledged"`
+	Created       time.Time          `json:"created,omitempty"`
+	Expires       time.Time          `json:"expires,omitempty"`
+	Artifacts     []*Artifact        `json:"artifacts,omitempty"`
+	Subcomponents []*ComponentStatus `json:"subcomponents,omitempty"`
+}
+
+// Artifact describes debug artifact attached to the health event.
+type Artifact struct {
+	ID       string          `json:"id"`
+	Type     string          `json:"type"`
+	Name     string          `json:"name,omitempty"`
+	Path     string          `json:"path,omitempty"`
+	MIMEType string          `json:"mimeType,omitempty"`
+	Size     int64           `json:"size,omitempty"`
+	Hash     *types.HashType `json:"-"`
+}
+
+// OpenFunc is called with the artifact header received from the target and returns writer for the artifact content.
+type OpenFunc func(a *Artifact) (io.Writer, error)
+
+// Client executes gNOI Healthz RPCs over the shared session.
+type Client struct {
+	t  *lib.SRLTarget
+	hc healthz.HealthzClient
+}
+
+// Creates Healthz client over provided session.
+func NewClient(s *session.Session) *Client {
+	return &Client{t: s.Target(), hc: healthz.NewHealthzClient(s.Conn())}
+}
+
+// Returns the latest health status of the component.
+func Get(t *lib.SRLTarget, component string) (*ComponentStatus, error) {
+	var cs *ComponentStatus
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		cs, err = c.Get(component)
+		return err
+	})
+	return cs, err
+}
+
+// Returns the latest health status of the component.
+func (c *Client) Get(component string) (*ComponentStatus, error) {
+	var gResp *healthz.GetResponse
+	err := c.t.Retry.Do(session.Retryable, func() error {
+		// Creating context with timeout.
+		ctx, cancel := context.WithTimeout(context.Background(), *c.t.Timeout)
+		defer cancel()
+
+		var err error
+		gResp, err = c.hc.Get(ctx, &healthz.GetRequest{Path: componentPath(component)})
+		if err != nil {
+			return fmt.Errorf("can't exec Get request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if gResp.GetComponent() == nil {
+		return nil, fmt.Errorf("no status received for component %s", component)
+	}
+	return statusFromGNOI(gResp.GetComponent()), nil
+}
+
+// Returns all health events of the component, acknowledged ones are included if includeAck is set.
+func List(t *lib.SRLTarget, component string, includeAck bool) ([]*ComponentStatus, error) {
+	var css []*ComponentStatus
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		css, err = c.List(component, includeAck)
+		return err
+	})
+	return css, err
+}
+
+// Returns all health events of the component, acknowledged ones are included if includeAck is set.
+func (c *Client) List(component string, includeAck bool) ([]*ComponentStatus, error) {
+	var lResp *healthz.ListResponse
+	err := c.t.Retry.Do(session.Retryable, func() error {
+		// Creating context with timeout.
+		ctx, cancel := context.WithTimeout(context.Background(), *c.t.Timeout)
+		defer cancel()
+
+		var err error
+		lResp, err = c.hc.List(ctx, &healthz.ListRequest{Path: componentPath(component), IncludeAcknowledged: includeAck})
+		if err != nil {
+			return fmt.Errorf("can't exec List request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	css := make([]*ComponentStatus, 0, len(lResp.GetStatuses()))
+	for _, s := range lResp.GetStatuses() {
+		css = append(css, statusFromGNOI(s))
+	}
+	return css, nil
+}
+
+// Streams the artifact content into the writer returned by open, which is called once the artifact header is received.
+// Returns the artifact header.
+func (c *Client) Artifact(id string, open OpenFunc) (*Artifact, error) {
+	// Artifact stream is not retried, since content is written already.
+	// Creating context cancelled on inactivity.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idle := time.AfterFunc(*c.t.Timeout, cancel)
+	defer idle.Stop()
+
+	aStream, err := c.hc.Artifact(ctx, &healthz.ArtifactRequest{Id: id})
+	if err != nil {
+		return nil, fmt.Errorf("can't exec Artifact request: %w", err)
+	}
+
+	var a *Artifact
+	var w io.Writer
+	for {
+		aResp, err := aStream.Recv()
+		if err == io.EOF {
+			return nil, fmt.Errorf("artifact %s stream closed before trailer", id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't get ArtifactResponse: %w", err)
+		}
+		idle.Reset(*c.t.Timeout)
+
+		if h := aResp.GetHeader(); h != nil {
+			a = artifactFromGNOI(h)
+			w, err = open(a)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if aResp.GetTrailer() != nil {
+			if a == nil {
+				return nil, fmt.Errorf("artifact %s: trailer received without header", id)
+			}
+			return a, nil
+		}
+		if w == nil {
+			return nil, fmt.Errorf("artifact %s: content received before header", id)
+		}
+		var b []byte
+		if p := aResp.GetProto(); p != nil {
+			b = p.GetValue()
+		} else {
+			b = aResp.GetBytes()
+		}
+		if _, err := w.Write(b); err != nil {
+			return nil, fmt.Errorf("unable to write data: %w", err)
+		}
+	}
+}
+
+// Saves the artifact into dir using the same writer GetFile does and verifies its hash, if provided by the target.
+// Returns the name of the file.
+func SaveArtifact(t *lib.SRLTarget, id string, dir string) (string, error) {
+	var lFile string
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		lFile, err = c.SaveArtifact(id, dir)
+		return err
+	})
+	return lFile, err
+}
+
+// Saves the artifact into dir using the same writer GetFile does and verifies its hash, if provided by the target.
+// Returns the name of the file.
+func (c *Client) SaveArtifact(id string, dir string) (string, error) {
+	var fw *file.Writer
+	var lFile string
+	a, err := c.Artifact(id, func(a *Artifact) (io.Writer, error) {
+		lFile = filepath.Join(dir, artifactFileName(a))
+		var err error
+		if a.Hash != nil {
+			fw, err = file.CreateHashWriter(lFile, a.Hash.GetMethod())
+		} else {
+			fw, err = file.CreateWriter(lFile)
+		}
+		return fw, err
+	})
+	if err != nil {
+		if fw != nil {
+			fw.Abort()
+		}
+		return "", err
+	}
+	if a.Hash != nil {
+		err = fw.Verify(a.Hash)
+		if err != nil {
+			fw.Abort()
+			return "", err
+		}
+	}
+	err = fw.Close()
+	if err != nil {
+		os.Remove(lFile)
+		return "", err
+	}
+	return lFile, nil
+}
+
+// Function returns local file name of the artifact: base name of the remote file or artifact id.
+func artifactFileName(a *Artifact) string {
+	name := filepath.Base(a.Name)
+	if a.Name == "" || name == "." || name == ".." || name == "/" {
+		name = a.ID
+	}
+	// Artifact id is chosen by the target, so it must not escape the directory.
+	return strings.ReplaceAll(name, string(filepath.Separator), "_")
+}
+
+// Function converts gNOI component status into ComponentStatus.
+func statusFromGNOI(s *healthz.ComponentStatus) *ComponentStatus {
+	cs := &ComponentStatus{
+		Component:    componentName(s.GetPath()),
+		Path:         pathString(s.GetPath()),
+		ID:           s.GetId(),
+		Status:       strings.ToLower(strings.TrimPrefix(s.GetStatus().String(), "STATUS_")),
+		Healthy:      s.GetStatus() == healthz.Status_STATUS_HEALTHY,
+		Acknowledged: s.GetAcknowledged(),
+	}
+	if s.GetCreated() != nil {
+		cs.Created = s.GetCreated().AsTime()
+	}
+	if s.GetExpires() != nil {
+		cs.Expires = s.GetExpires().AsTime()
+	}
+	for _, h := range s.GetArtifacts() {
+		cs.Artifacts = append(cs.Artifacts, artifactFromGNOI(h))
+	}
+	for _, sub := range s.GetSubcomponents() {
+		cs.Subcomponents = append(cs.Subcomponents, statusFromGNOI(sub))
+	}
+	return cs
+}
+
+// Function converts gNOI artifact header into Artifact.
+func artifactFromGNOI(h *healthz.ArtifactHeader) *Artifact {
+	a := &Artifact{ID: h.GetId()}
+	switch {
+	case h.GetFile() != nil:
+		f := h.GetFile()
+		a.Type = ArtifactFile
+		a.Name = f.GetName()
+		a.Path = f.GetPath()
+		a.MIMEType = f.GetMimetype()
+		a.Size = f.GetSize()
+		a.Hash = f.GetHash()
+	case h.GetProto() != nil:
+		a.Type = ArtifactProto
+	case h.GetCustom() != nil:
+		a.Type = ArtifactCustom
+		a.MIMEType = h.GetCustom().GetTypeUrl()
+	}
+	return a
+}
+
+// Function returns gNOI path of the component.
+func componentPath(name string) *types.Path {
+	return &types.Path{Elem: []*types.PathElem{
+		{Name: "components"},
+		{Name: "component", Key: map[string]string{"name": name}},
+	}}
+}
+
+// Function returns the name of the component from its path or empty string, if path has no name key.
+func componentName(p *types.Path) string {
+	elems := p.GetElem()
+	if len(elems) == 0 {
+		return ""
+	}
+	return elems[len(elems)-1].GetKey()["name"]
+}
+
+// Function returns string representation of the path, e.g. /components/component[name=Linecard1].
+func pathString(p *types.Path) string {
+	var sb strings.Builder
+	for _, e := range p.GetElem() {
+		sb.WriteString("/" + e.GetName())
+		keys := make([]string, 0, len(e.GetKey()))
+		for k := range e.GetKey() {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sb.WriteString("[" + k + "=" + e.GetKey()[k] + "]")
+		}
+	}
+	return sb.String()
+}
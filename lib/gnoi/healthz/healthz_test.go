@@ -0,0 +1,171 @@
+package healthz
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib/gnoi/file"
+	"github.com/azyablov/fat/lib/session/sessiontest"
+	"github.com/openconfig/gnoi/healthz"
+	"github.com/openconfig/gnoi/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var created = time.Date(2023, 3, 17, 11, 47, 0, 0, time.UTC)
+
+// fakeHealthzServer reports single unhealthy linecard with core dump artifact.
+type fakeHealthzServer struct {
+	healthz.UnimplementedHealthzServer
+	dump []byte
+	hash []byte // Hash reported for the dump, sha256 of dump if nil.
+}
+
+func (s *fakeHealthzServer) event() *healthz.ComponentStatus {
+	hash := s.hash
+	if hash == nil {
+		sum := sha256.Sum256(s.dump)
+		hash = sum[:]
+	}
+	return &healthz.ComponentStatus{
+		Path:    componentPath("Linecard1"),
+		Id:      "evt-1",
+		Status:  healthz.Status_STATUS_UNHEALTHY,
+		Created: timestamppb.New(created),
+		Artifacts: []*healthz.ArtifactHeader{{
+			Id: "core-1",
+			ArtifactType: &healthz.ArtifactHeader_File{File: &healthz.FileArtifactType{
+				Name: "/var/core/sr_linecard.core",
+				Size: int64(len(s.dump)),
+				Hash: &types.HashType{Method: types.HashType_SHA256, Hash: hash},
+			}},
+		}},
+	}
+}
+
+func (s *fakeHealthzServer) Get(ctx context.Context, req *healthz.GetRequest) (*healthz.GetResponse, error) {
+	if componentName(req.GetPath()) != "Linecard1" {
+		return nil, status.Errorf(codes.NotFound, "unknown component")
+	}
+	return &healthz.GetResponse{Component: s.event()}, nil
+}
+
+func (s *fakeHealthzServer) List(ctx context.Context, req *healthz.ListRequest) (*healthz.ListResponse, error) {
+	statuses := []*healthz.ComponentStatus{s.event()}
+	if req.GetIncludeAcknowledged() {
+		statuses = append(statuses, &healthz.ComponentStatus{Path: req.GetPath(), Id: "evt-0", Status: healthz.Status_STATUS_HEALTHY, Acknowledged: true})
+	}
+	return &healthz.ListResponse{Statuses: statuses}, nil
+}
+
+func (s *fakeHealthzServer) Artifact(req *healthz.ArtifactRequest, stream healthz.Healthz_ArtifactServer) error {
+	if req.GetId() != "core-1" {
+		return status.Errorf(codes.NotFound, "unknown artifact")
+	}
+	if err := stream.Send(&healthz.ArtifactResponse{Contents: &healthz.ArtifactResponse_Header{Header: s.event().GetArtifacts()[0]}}); err != nil {
+		return err
+	}
+	for i := 0; i < len(s.dump); i += 4 {
+		end := i + 4
+		if end > len(s.dump) {
+			end = len(s.dump)
+		}
+		if err := stream.Send(&healthz.ArtifactResponse{Contents: &healthz.ArtifactResponse_Bytes{Bytes: s.dump[i:end]}}); err != nil {
+			return err
+		}
+	}
+	return stream.Send(&healthz.ArtifactResponse{Contents: &healthz.ArtifactResponse_Trailer{Trailer: &healthz.ArtifactTrailer{}}})
+}
+
+// Starts fake gNOI Healthz server and returns client connected to it.
+func newFakeClient(t *testing.T, srv healthz.HealthzServer) *Client {
+	t.Helper()
+	conn := sessiontest.NewConn(t, func(s *grpc.Server) { healthz.RegisterHealthzServer(s, srv) })
+	return &Client{t: sessiontest.NewTarget(), hc: healthz.NewHealthzClient(conn)}
+}
+
+func TestGetAndList(t *testing.T) {
+	c := newFakeClient(t, &fakeHealthzServer{dump: []byte("core")})
+
+	cs, err := c.Get("Linecard1")
+	if err != nil {
+		t.Fatalf("got an error from Get(): %v", err)
+	}
+	if cs.Component != "Linecard1" || cs.Path != "/components/component[name=Linecard1]" || cs.Healthy || cs.Status != "unhealthy" || !cs.Created.Equal(created) {
+		t.Errorf("incorrect component status: %+v", cs)
+	}
+	if len(cs.Artifacts) != 1 || cs.Artifacts[0].Type != ArtifactFile || cs.Artifacts[0].Name != "/var/core/sr_linecard.core" {
+		t.Errorf("incorrect artifacts: %+v", cs.Artifacts)
+	}
+	if _, err := c.Get("Linecard2"); status.Code(errors.Unwrap(err)) != codes.NotFound {
+		t.Errorf("expected NotFound error; got: %v", err)
+	}
+
+	css, err := c.List("Linecard1", false)
+	if err != nil {
+		t.Fatalf("got an error from List(): %v", err)
+	}
+	if len(css) != 1 {
+		t.Errorf("expected 1 event; got: %d", len(css))
+	}
+	css, err = c.List("Linecard1", true)
+	if err != nil {
+		t.Fatalf("got an error from List(): %v", err)
+	}
+	if len(css) != 2 || !css[1].Acknowledged || !css[1].Healthy {
+		t.Errorf("expected acknowledged event to be included; got: %+v", css)
+	}
+}
+
+func TestSaveArtifact(t *testing.T) {
+	dump := []byte("linecard core dump content")
+	dir := t.TempDir()
+	c := newFakeClient(t, &fakeHealthzServer{dump: dump})
+
+	lFile, err := c.SaveArtifact("core-1", dir)
+	if err != nil {
+		t.Fatalf("got an error from SaveArtifact(): %v", err)
+	}
+	if lFile != filepath.Join(dir, "sr_linecard.core") {
+		t.Errorf("unexpected file name: %s", lFile)
+	}
+	b, err := os.ReadFile(lFile)
+	if err != nil || string(b) != string(dump) {
+		t.Errorf("incorrect artifact content: %q, %v", b, err)
+	}
+
+	// Corrupted artifact is not kept.
+	dir = t.TempDir()
+	c = newFakeClient(t, &fakeHealthzServer{dump: dump, hash: []byte("wrong")})
+	_, err = c.SaveArtifact("core-1", dir)
+	var mErr *file.ChecksumMismatchError
+	if !errors.As(err, &mErr) {
+		t.Errorf("expected *file.ChecksumMismatchError; got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sr_linecard.core")); !os.IsNotExist(err) {
+		t.Errorf("expected corrupted artifact to be removed; got: %v", err)
+	}
+}
+
+func TestArtifactFileName(t *testing.T) {
+	tests := []struct {
+		a    *Artifact
+		name string
+	}{
+		{&Artifact{ID: "core-1", Name: "/var/core/sr_linecard.core"}, "sr_linecard.core"},
+		{&Artifact{ID: "core-1"}, "core-1"},
+		{&Artifact{ID: "a/../b", Name: ".."}, "a_.._b"},
+	}
+	for _, tt := range tests {
+		if got := artifactFileName(tt.a); got != tt.name {
+			t.Errorf("artifactFileName(%+v) = %s; want: %s", tt.a, got, tt.name)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+// Package os wraps gNOI OS service RPCs to install, activate and verify software images.
+package os
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session"
+	gnoios "github.com/openconfig/gnoi/os"
+)
+
+// Size of the chunk used to stream the image toward the target.
+const InstallChunkSize = 64 * 1024
+
+// Default time the target is given to validate the image once it's transferred.
+const DefaultValidationTimeout = 10 * time.Minute
+
+// ProgressFunc is called after every chunk sent and every progress reported by the target
+// with the number of bytes sent and received by the target so far.
+type ProgressFunc func(sent int64, received uint64)
+
+// Client executes gNOI OS RPCs over the shared session.
+type Client struct {
+	t  *lib.SRLTarget
+	oc gnoios.OSClient
+}
+
+// Creates OS client over provided session.
+func NewClient(s *session.Session) *Client {
+	return &Client{t: s.Target(), oc: gnoios.NewOSClient(s.Conn())}
+}
+
+// InstallOptions describe the image to be installed.
+type InstallOptions struct {
+	Version           string        // Version of the image, e.g. 23.3.1-343.
+	StandbySupervisor bool          // Install the image on standby supervisor.
+	ValidationTimeout time.Duration // Time the target is given to validate the image, DefaultValidationTimeout if zero.
+}
+
+// InstallResult is the outcome of the image installation.
+type InstallResult struct {
+	Version     string // Version validated by the target.
+	Description string // Description of the image provided by the target.
+	Sent        int64  // Number of bytes sent.
+	Skipped     bool   // The target had the image already, so nothing was transferred.
+}
+
+// VerifyResult describes software running on the target.
+type VerifyResult struct {
+	Version               string
+	ActivationFailMessage string // Reason the last activation failed, if any.
+}
+
+// Installs the image from local file on the target, see InstallReader().
+func Install(t *lib.SRLTarget, image string, opts *InstallOptions, progress ProgressFunc) (*InstallResult, error) {
+	f, err := os.Open(image)
+	if err != nil {
+		return nil, fmt.Errorf("can't open image: %s", err)
+	}
+	defer f.Close()
+
+	var res *InstallResult
+	err = session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		res, err = c.InstallReader(f, opts, progress)
+		return err
+	})
+	return res, err
+}
+
+// installMsg carries the response received from the install stream.
+type installMsg struct {
+	resp *gnoios.InstallResponse
+	err  error
+}
+
+// Streams the image from r to the target in chunks and waits for validation.
+// If the target reports the version is present already, transfer is skipped, e.g. when an upgrade interrupted
+// after the image had been validated is run again. gNOI OS Install has no means to continue from an offset,
+// so interrupted transfer starts over from the first byte.
+// Target timeout is applied as inactivity timeout during transfer.
+func (c *Client) InstallReader(r io.Reader, opts *InstallOptions, progress ProgressFunc) (*InstallResult, error) {
+	if opts == nil || len(opts.Version) == 0 {
+		return nil, fmt.Errorf("version is mandatory, but missed")
+	}
+	validationTimeout := opts.ValidationTimeout
+	if validationTimeout == 0 {
+		validationTimeout = DefaultValidationTimeout
+	}
+
+	// Creating context cancelled on inactivity.
+	timeout := *c.t.Timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idle := time.AfterFunc(timeout, cancel)
+	defer idle.Stop()
+
+	// Content of r can't be replayed, so only stream opening is retried.
+	var iStream gnoios.OS_InstallClient
+	err := c.t.Retry.Do(session.Retryable, func() error {
+		var err error
+		iStream, err = c.oc.Install(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't exec Install request: %w", err)
+	}
+
+	// Responses are received in background, since the target reports progress while the image is sent.
+	recv := make(chan installMsg)
+	go func() {
+		for {
+			resp, err := iStream.Recv()
+			select {
+			case recv <- installMsg{resp: resp, err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	res := new(InstallResult)
+	var received uint64
+	// Function handles received message and returns response, if it's neither progress nor error.
+	handle := func(m installMsg) (*gnoios.InstallResponse, error) {
+		if m.err != nil {
+			return nil, fmt.Errorf("can't get InstallResponse: %w", m.err)
+		}
+		idle.Reset(timeout)
+		switch {
+		case m.resp.GetTransferProgress() != nil:
+			received = m.resp.GetTransferProgress().GetBytesReceived()
+			if progress != nil {
+				progress(res.Sent, received)
+			}
+			return nil, nil
+		case m.resp.GetSyncProgress() != nil:
+			return nil, nil
+		case m.resp.GetInstallError() != nil:
+			e := m.resp.GetInstallError()
+			return nil, fmt.Errorf("install error %s: %s", e.GetType(), e.GetDetail())
+		}
+		return m.resp, nil
+	}
+	// Function waits for the response, which is neither progress nor error.
+	next := func() (*gnoios.InstallResponse, error) {
+		for {
+			select {
+			case m := <-recv:
+				resp, err := handle(m)
+				if err != nil || resp != nil {
+					return resp, err
+				}
+			case <-ctx.Done():
+				return nil, fmt.Errorf("no response from the target: %w", ctx.Err())
+			}
+		}
+	}
+
+	err = iStream.Send(&gnoios.InstallRequest{Request: &gnoios.InstallRequest_TransferRequest{TransferRequest: &gnoios.TransferRequest{
+		Version:           opts.Version,
+		StandbySupervisor: opts.StandbySupervisor,
+	}}})
+	if err != nil {
+		return nil, fmt.Errorf("can't send TransferRequest: %w", err)
+	}
+	resp, err := next()
+	if err != nil {
+		return nil, err
+	}
+	if v := resp.GetValidated(); v != nil {
+		// The image is present on the target already.
+		res.Version, res.Description, res.Skipped = v.GetVersion(), v.GetDescription(), true
+		return res, iStream.CloseSend()
+	}
+	if resp.GetTransferReady() == nil {
+		return nil, fmt.Errorf("unexpected response to TransferRequest: %v", resp)
+	}
+
+	buf := make([]byte, InstallChunkSize)
+	for {
+		n, rErr := io.ReadFull(r, buf)
+		if n > 0 {
+			err = iStream.Send(&gnoios.InstallRequest{Request: &gnoios.InstallRequest_TransferContent{TransferContent: append([]byte(nil), buf[:n]...)}})
+			if err != nil {
+				return res, fmt.Errorf("can't send image content: %w", err)
+			}
+			idle.Reset(timeout)
+			res.Sent += int64(n)
+			if progress != nil {
+				progress(res.Sent, received)
+			}
+		}
+		if rErr == io.EOF || rErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rErr != nil {
+			return res, fmt.Errorf("can't read image: %w", rErr)
+		}
+		// Handling responses received so far w/o blocking.
+		for pending := true; pending; {
+			select {
+			case m := <-recv:
+				resp, err := handle(m)
+				if err != nil {
+					return res, err
+				}
+				if resp != nil {
+					return res, fmt.Errorf("unexpected response during transfer: %v", resp)
+				}
+			default:
+				pending = false
+			}
+		}
+	}
+
+	err = iStream.Send(&gnoios.InstallRequest{Request: &gnoios.InstallRequest_TransferEnd{TransferEnd: &gnoios.TransferEnd{}}})
+	if err != nil {
+		return res, fmt.Errorf("can't send TransferEnd: %w", err)
+	}
+	// Validation could take a while.
+	timeout = validationTimeout
+	idle.Reset(timeout)
+	resp, err = next()
+	if err != nil {
+		return res, err
+	}
+	v := resp.GetValidated()
+	if v == nil {
+		return res, fmt.Errorf("unexpected response to TransferEnd: %v", resp)
+	}
+	if v.GetVersion() != opts.Version {
+		return res, fmt.Errorf("target validated version %s, but %s was expected", v.GetVersion(), opts.Version)
+	}
+	res.Version, res.Description = v.GetVersion(), v.GetDescription()
+	return res, iStream.CloseSend()
+}
+
+// Activates installed version, the target reboots unless noReboot is set.
+func Activate(t *lib.SRLTarget, version string, noReboot bool) error {
+	return session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		return NewClient(s).Activate(version, noReboot)
+	})
+}
+
+// Activates installed version, the target reboots unless noReboot is set.
+func (c *Client) Activate(version string, noReboot bool) error {
+	if len(version) == 0 {
+		return fmt.Errorf("version is mandatory, but missed")
+	}
+	// Creating context with timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), *c.t.Timeout)
+	defer cancel()
+
+	// Activation isn't idempotent, so it's never retried.
+	aResp, err := c.oc.Activate(ctx, &gnoios.ActivateRequest{Version: version, NoReboot: noReboot})
+	if err != nil {
+		return fmt.Errorf("can't exec Activate request: %w", err)
+	}
+	if e := aResp.GetActivateError(); e != nil {
+		return fmt.Errorf("activate error %s: %s", e.GetType(), e.GetDetail())
+	}
+	return nil
+}
+
+// Returns version running on the target.
+func Verify(t *lib.SRLTarget) (*VerifyResult, error) {
+	var res *VerifyResult
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		res, err = c.Verify()
+		return err
+	})
+	return res, err
+}
+
+// Returns version running on the target.
+func (c *Client) Verify() (*VerifyResult, error) {
+	var vResp *gnoios.VerifyResponse
+	err := c.t.Retry.Do(session.Retryable, func() error {
+		// Creating context with timeout.
+		ctx, cancel := context.WithTimeout(context.Background(), *c.t.Timeout)
+		defer cancel()
+
+		var err error
+		vResp, err = c.oc.Verify(ctx, &gnoios.VerifyRequest{})
+		if err != nil {
+			return fmt.Errorf("can't exec Verify request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyResult{Version: vResp.GetVersion(), ActivationFailMessage: vResp.GetActivationFailMessage()}, nil
+}
+
+// Waits till the target runs the version, e.g. after activation and reboot, polling it every interval.
+func WaitForVersion(ctx context.Context, t *lib.SRLTarget, version string, interval time.Duration, timeout time.Duration) error {
+	return session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		return NewClient(s).WaitForVersion(ctx, version, interval, timeout)
+	})
+}
+
+// Waits till the target runs the version, polling it every interval.
+// Errors are expected while the target reboots, so polling continues till timeout expires, ctx is done
+// or activation failure is reported.
+func (c *Client) WaitForVersion(ctx context.Context, version string, interval time.Duration, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+	for {
+		res, err := c.Verify()
+		switch {
+		case err != nil:
+			lastErr = err
+		case res.Version == version:
+			return nil
+		case len(res.ActivationFailMessage) != 0:
+			return fmt.Errorf("activation of %s failed: %s", version, res.ActivationFailMessage)
+		default:
+			lastErr = fmt.Errorf("target runs version %s", res.Version)
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("version %s isn't running after %s: %w", version, timeout, lastErr)
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("waiting for version %s aborted: %w", version, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package os
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib/session/sessiontest"
+	gnoios "github.com/openconfig/gnoi/os"
+	"google.golang.org/grpc"
+)
+
+// fakeOSServer accepts images of the new version, reports progress per chunk and
+// runs old version till verified the specified number of times after activation.
+type fakeOSServer struct {
+	gnoios.UnimplementedOSServer
+	mu        sync.Mutex
+	installed map[string][]byte
+	running   string
+	activated string
+	verifies  int // Verify calls after activation returning old version.
+}
+
+func (s *fakeOSServer) Install(stream gnoios.OS_InstallServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	version := req.GetTransferRequest().GetVersion()
+	s.mu.Lock()
+	_, ok := s.installed[version]
+	s.mu.Unlock()
+	if ok {
+		return stream.Send(&gnoios.InstallResponse{Response: &gnoios.InstallResponse_Validated{Validated: &gnoios.Validated{Version: version}}})
+	}
+	if strings.HasPrefix(version, "bad") {
+		return stream.Send(&gnoios.InstallResponse{Response: &gnoios.InstallResponse_InstallError{InstallError: &gnoios.InstallError{Detail: "incompatible version"}}})
+	}
+	if err := stream.Send(&gnoios.InstallResponse{Response: &gnoios.InstallResponse_TransferReady{TransferReady: &gnoios.TransferReady{}}}); err != nil {
+		return err
+	}
+
+	image := new(bytes.Buffer)
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if req.GetTransferEnd() != nil {
+			break
+		}
+		image.Write(req.GetTransferContent())
+		err = stream.Send(&gnoios.InstallResponse{Response: &gnoios.InstallResponse_TransferProgress{TransferProgress: &gnoios.TransferProgress{BytesReceived: uint64(image.Len())}}})
+		if err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	s.installed[version] = image.Bytes()
+	s.mu.Unlock()
+	return stream.Send(&gnoios.InstallResponse{Response: &gnoios.InstallResponse_Validated{Validated: &gnoios.Validated{Version: version, Description: "SR Linux " + version}}})
+}
+
+func (s *fakeOSServer) Activate(ctx context.Context, req *gnoios.ActivateRequest) (*gnoios.ActivateResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.installed[req.GetVersion()]; !ok {
+		return &gnoios.ActivateResponse{Response: &gnoios.ActivateResponse_ActivateError{ActivateError: &gnoios.ActivateError{Detail: "not installed"}}}, nil
+	}
+	s.activated = req.GetVersion()
+	return &gnoios.ActivateResponse{Response: &gnoios.ActivateResponse_ActivateOk{ActivateOk: &gnoios.ActivateOK{}}}, nil
+}
+
+func (s *fakeOSServer) Verify(ctx context.Context, req *gnoios.VerifyRequest) (*gnoios.VerifyResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.activated != "" && s.verifies == 0 {
+		s.running = s.activated
+	}
+	s.verifies--
+	return &gnoios.VerifyResponse{Version: s.running}, nil
+}
+
+// Starts fake gNOI OS server and returns client connected to it.
+func newFakeClient(t *testing.T, srv gnoios.OSServer) *Client {
+	t.Helper()
+	conn := sessiontest.NewConn(t, func(s *grpc.Server) { gnoios.RegisterOSServer(s, srv) })
+	return &Client{t: sessiontest.NewTarget(), oc: gnoios.NewOSClient(conn)}
+}
+
+func TestInstall(t *testing.T) {
+	image := bytes.Repeat([]byte("srlinux"), InstallChunkSize/2)
+	srv := &fakeOSServer{installed: map[string][]byte{"23.3.1-343": nil}, running: "23.3.1-343"}
+	c := newFakeClient(t, srv)
+
+	var lastSent int64
+	res, err := c.InstallReader(bytes.NewReader(image), &InstallOptions{Version: "23.7.1-163"}, func(sent int64, received uint64) {
+		lastSent = sent
+	})
+	if err != nil {
+		t.Fatalf("got an error from InstallReader(): %v", err)
+	}
+	if res.Skipped || res.Sent != int64(len(image)) || res.Version != "23.7.1-163" || lastSent != int64(len(image)) {
+		t.Errorf("incorrect install result: %+v", res)
+	}
+	if !bytes.Equal(srv.installed["23.7.1-163"], image) {
+		t.Errorf("image is corrupted on the target")
+	}
+
+	// Second attempt is skipped, since the image is present already.
+	res, err = c.InstallReader(bytes.NewReader(image), &InstallOptions{Version: "23.7.1-163"}, nil)
+	if err != nil {
+		t.Fatalf("got an error from InstallReader(): %v", err)
+	}
+	if !res.Skipped || res.Sent != 0 {
+		t.Errorf("expected skipped install; got: %+v", res)
+	}
+
+	_, err = c.InstallReader(bytes.NewReader(image), &InstallOptions{Version: "bad-1"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "incompatible version") {
+		t.Errorf("expected install error; got: %v", err)
+	}
+}
+
+func TestActivateAndWait(t *testing.T) {
+	srv := &fakeOSServer{installed: map[string][]byte{"23.3.1-343": nil, "23.7.1-163": nil}, running: "23.3.1-343", verifies: 2}
+	c := newFakeClient(t, srv)
+
+	if err := c.Activate("24.3.1-1", false); err == nil || !strings.Contains(err.Error(), "not installed") {
+		t.Errorf("expected activate error; got: %v", err)
+	}
+	if err := c.Activate("23.7.1-163", false); err != nil {
+		t.Fatalf("got an error from Activate(): %v", err)
+	}
+	if err := c.WaitForVersion(context.Background(), "23.7.1-163", 10*time.Millisecond, time.Second); err != nil {
+		t.Fatalf("got an error from WaitForVersion(): %v", err)
+	}
+
+	err := c.WaitForVersion(context.Background(), "24.3.1-1", 10*time.Millisecond, 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("runs version %s", "23.7.1-163")) {
+		t.Errorf("expected timeout error; got: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = c.WaitForVersion(ctx, "24.3.1-1", time.Hour, 2*time.Hour)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected wait aborted by context; got: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session"
+	"github.com/openconfig/gnoi/system"
+)
+
+// PingOptions are optional parameters of ping, zero values mean target defaults.
+type PingOptions struct {
+	Source        string        // Source address to ping from.
+	Count         int32         // Number of packets.
+	Interval      time.Duration // Interval between packets.
+	Wait          time.Duration // Time to wait for a response.
+	Size          int32         // Size of the packet.
+	DoNotFragment bool          // Set DF bit.
+	DoNotResolve  bool          // Don't resolve addresses to names.
+}
+
+// PingReply is the response to a single packet.
+type PingReply struct {
+	Source   string        // Address of the responder.
+	RTT      time.Duration // Round trip time.
+	Bytes    int32         // Size of the response.
+	Sequence int32         // Sequence number of the packet.
+	TTL      int32         // TTL of the response.
+}
+
+// PingResult holds all replies received and summary sent by the target at the end.
+type PingResult struct {
+	Destination string
+	Replies     []PingReply
+	Sent        int32
+	Received    int32
+	MinRTT      time.Duration
+	AvgRTT      time.Duration
+	MaxRTT      time.Duration
+	StdDev      time.Duration
+}
+
+// Pings destination from the target, opts could be nil.
+func Ping(t *lib.SRLTarget, dst string, opts *PingOptions) (*PingResult, error) {
+	var res *PingResult
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		res, err = c.Ping(dst, opts)
+		return err
+	})
+	return res, err
+}
+
+// Pings destination from the target, opts could be nil.
+// Target timeout is applied as inactivity timeout between responses.
+func (c *Client) Ping(dst string, opts *PingOptions) (*PingResult, error) {
+	if len(dst) == 0 {
+		return nil, fmt.Errorf("destination can't be null string")
+	}
+	if opts == nil {
+		opts = new(PingOptions)
+	}
+	pReq := &system.PingRequest{
+		Destination:   dst,
+		Source:        opts.Source,
+		Count:         opts.Count,
+		Interval:      int64(opts.Interval),
+		Wait:          int64(opts.Wait),
+		Size:          opts.Size,
+		DoNotFragment: opts.DoNotFragment,
+		DoNotResolve:  opts.DoNotResolve,
+	}
+
+	var res *PingResult
+	// Ping has no side effects, so it's restarted from scratch on retry.
+	err := c.t.Retry.Do(session.Retryable, func() error {
+		// Creating context cancelled on inactivity.
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		idle := time.AfterFunc(*c.t.Timeout, cancel)
+		defer idle.Stop()
+
+		pStream, err := c.sc.Ping(ctx, pReq)
+		if err != nil {
+			return fmt.Errorf("can't exec Ping request: %w", err)
+		}
+
+		res = &PingResult{Destination: dst}
+		for {
+			pResp, err := pStream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("can't get PingResponse: %w", err)
+			}
+			idle.Reset(*c.t.Timeout)
+			// Summary is distinguished by the number of packets sent.
+			if pResp.GetSent() != 0 {
+				res.Sent = pResp.GetSent()
+				res.Received = pResp.GetReceived()
+				res.MinRTT = time.Duration(pResp.GetMinTime())
+				res.AvgRTT = time.Duration(pResp.GetAvgTime())
+				res.MaxRTT = time.Duration(pResp.GetMaxTime())
+				res.StdDev = time.Duration(pResp.GetStdDev())
+				continue
+			}
+			res.Replies = append(res.Replies, PingReply{
+				Source:   pResp.GetSource(),
+				RTT:      time.Duration(pResp.GetTime()),
+				Bytes:    pResp.GetBytes(),
+				Sequence: pResp.GetSequence(),
+				TTL:      pResp.GetTtl(),
+			})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
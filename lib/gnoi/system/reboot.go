@@ -0,0 +1,98 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session"
+	"github.com/openconfig/gnoi/system"
+)
+
+// Reboot methods supported by SR Linux.
+const (
+	RebootCold = system.RebootMethod_COLD
+	RebootWarm = system.RebootMethod_WARM
+	RebootHalt = system.RebootMethod_HALT
+	RebootNSF  = system.RebootMethod_NSF
+)
+
+// RebootStatus describes pending or ongoing reboot of the target.
+type RebootStatus struct {
+	Active bool          // Reboot is pending.
+	Wait   time.Duration // Time left till reboot.
+	When   time.Time     // Time reboot is scheduled for.
+	Reason string        // Reboot message.
+	Count  uint32        // Number of reboots since the active control processor started.
+}
+
+// Reboots the target after specified delay, message is logged by the target as the reason.
+func Reboot(t *lib.SRLTarget, method system.RebootMethod, delay time.Duration, message string) error {
+	return session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		return NewClient(s).Reboot(method, delay, message)
+	})
+}
+
+// Reboots the target after specified delay, message is logged by the target as the reason.
+func (c *Client) Reboot(method system.RebootMethod, delay time.Duration, message string) error {
+	if delay < 0 {
+		return fmt.Errorf("reboot delay can't be negative: %s", delay)
+	}
+	// Creating context with timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), *c.t.Timeout)
+	defer cancel()
+
+	// Reboot isn't idempotent, so it's never retried.
+	_, err := c.sc.Reboot(ctx, &system.RebootRequest{
+		Method:  method,
+		Delay:   uint64(delay),
+		Message: message,
+	})
+	if err != nil {
+		return fmt.Errorf("can't exec Reboot request: %w", err)
+	}
+	return nil
+}
+
+// Returns status of the pending reboot.
+func GetRebootStatus(t *lib.SRLTarget) (*RebootStatus, error) {
+	var st *RebootStatus
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		st, err = c.RebootStatus()
+		return err
+	})
+	return st, err
+}
+
+// Returns status of the pending reboot.
+func (c *Client) RebootStatus() (*RebootStatus, error) {
+	var rResp *system.RebootStatusResponse
+	err := c.t.Retry.Do(session.Retryable, func() error {
+		// Creating context with timeout.
+		ctx, cancel := context.WithTimeout(context.Background(), *c.t.Timeout)
+		defer cancel()
+
+		var err error
+		rResp, err = c.sc.RebootStatus(ctx, &system.RebootStatusRequest{})
+		if err != nil {
+			return fmt.Errorf("can't exec RebootStatus request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	st := &RebootStatus{
+		Active: rResp.GetActive(),
+		Wait:   time.Duration(rResp.GetWait()),
+		Reason: rResp.GetReason(),
+		Count:  rResp.GetCount(),
+	}
+	if rResp.GetWhen() != 0 {
+		st.When = time.Unix(0, int64(rResp.GetWhen()))
+	}
+	return st, nil
+}
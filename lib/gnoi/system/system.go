@@ -0,0 +1,114 @@
+// Package system wraps gNOI System service RPCs: reboot, ping, traceroute, time and switch-over of control processors.
+package system
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session"
+	"github.com/openconfig/gnoi/system"
+	"github.com/openconfig/gnoi/types"
+)
+
+// Client executes gNOI System RPCs over the shared session.
+type Client struct {
+	t  *lib.SRLTarget
+	sc system.SystemClient
+}
+
+// Creates System client over provided session.
+func NewClient(s *session.Session) *Client {
+	return &Client{t: s.Target(), sc: system.NewSystemClient(s.Conn())}
+}
+
+// SwitchoverResult is the outcome of control processor switch-over.
+type SwitchoverResult struct {
+	ControlProcessor string        // Name of the control processor became active.
+	Version          string        // Software version running on it.
+	Uptime           time.Duration // Uptime of the control processor.
+}
+
+// Returns current time of the target.
+func Time(t *lib.SRLTarget) (time.Time, error) {
+	var tm time.Time
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		tm, err = c.Time()
+		return err
+	})
+	return tm, err
+}
+
+// Returns current time of the target.
+func (c *Client) Time() (time.Time, error) {
+	var tResp *system.TimeResponse
+	err := c.t.Retry.Do(session.Retryable, func() error {
+		// Creating context with timeout.
+		ctx, cancel := context.WithTimeout(context.Background(), *c.t.Timeout)
+		defer cancel()
+
+		var err error
+		tResp, err = c.sc.Time(ctx, &system.TimeRequest{})
+		if err != nil {
+			return fmt.Errorf("can't exec Time request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, int64(tResp.GetTime())), nil
+}
+
+// Switches over to the specified control processor, e.g. B.
+func SwitchControlProcessor(t *lib.SRLTarget, cp string) (*SwitchoverResult, error) {
+	var res *SwitchoverResult
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		res, err = c.SwitchControlProcessor(cp)
+		return err
+	})
+	return res, err
+}
+
+// Switches over to the specified control processor, e.g. B.
+func (c *Client) SwitchControlProcessor(cp string) (*SwitchoverResult, error) {
+	if len(cp) == 0 {
+		return nil, fmt.Errorf("control processor can't be null string")
+	}
+	// Creating context with timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), *c.t.Timeout)
+	defer cancel()
+
+	// Switch-over isn't idempotent, so it's never retried.
+	sResp, err := c.sc.SwitchControlProcessor(ctx, &system.SwitchControlProcessorRequest{ControlProcessor: componentPath(cp)})
+	if err != nil {
+		return nil, fmt.Errorf("can't exec SwitchControlProcessor request: %w", err)
+	}
+	return &SwitchoverResult{
+		ControlProcessor: componentName(sResp.GetControlProcessor()),
+		Version:          sResp.GetVersion(),
+		Uptime:           time.Duration(sResp.GetUptime()),
+	}, nil
+}
+
+// Function returns path of the named component, i.e. /components/component[name=<name>].
+func componentPath(name string) *types.Path {
+	return &types.Path{Elem: []*types.PathElem{
+		{Name: "components"},
+		{Name: "component", Key: map[string]string{"name": name}},
+	}}
+}
+
+// Function returns the name of the component from its path or empty string, if path has no name key.
+func componentName(p *types.Path) string {
+	elems := p.GetElem()
+	if len(elems) == 0 {
+		return ""
+	}
+	return elems[len(elems)-1].GetKey()["name"]
+}
@@ -0,0 +1,163 @@
+package system
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib/session/sessiontest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnoi/system"
+	"google.golang.org/grpc"
+)
+
+// fakeSystemServer replies with canned responses and records the last reboot request.
+type fakeSystemServer struct {
+	system.UnimplementedSystemServer
+	reboot *system.RebootRequest
+}
+
+func (s *fakeSystemServer) Time(ctx context.Context, req *system.TimeRequest) (*system.TimeResponse, error) {
+	return &system.TimeResponse{Time: 1_700_000_000_000_000_000}, nil
+}
+
+func (s *fakeSystemServer) Reboot(ctx context.Context, req *system.RebootRequest) (*system.RebootResponse, error) {
+	s.reboot = req
+	return &system.RebootResponse{}, nil
+}
+
+func (s *fakeSystemServer) RebootStatus(ctx context.Context, req *system.RebootStatusRequest) (*system.RebootStatusResponse, error) {
+	return &system.RebootStatusResponse{Active: true, Wait: uint64(time.Minute), When: 1_700_000_060_000_000_000, Reason: "maintenance", Count: 3}, nil
+}
+
+func (s *fakeSystemServer) SwitchControlProcessor(ctx context.Context, req *system.SwitchControlProcessorRequest) (*system.SwitchControlProcessorResponse, error) {
+	return &system.SwitchControlProcessorResponse{ControlProcessor: req.GetControlProcessor(), Version: "v23.3.1", Uptime: int64(time.Hour)}, nil
+}
+
+func (s *fakeSystemServer) Ping(req *system.PingRequest, stream system.System_PingServer) error {
+	for i := int32(1); i <= req.GetCount(); i++ {
+		if err := stream.Send(&system.PingResponse{Source: req.GetDestination(), Time: int64(i) * int64(time.Millisecond), Bytes: 64, Sequence: i, Ttl: 64}); err != nil {
+			return err
+		}
+	}
+	return stream.Send(&system.PingResponse{Source: req.GetDestination(), Sent: req.GetCount(), Received: req.GetCount(),
+		MinTime: int64(time.Millisecond), AvgTime: int64(2 * time.Millisecond), MaxTime: int64(3 * time.Millisecond)})
+}
+
+func (s *fakeSystemServer) Traceroute(req *system.TracerouteRequest, stream system.System_TracerouteServer) error {
+	resps := []*system.TracerouteResponse{
+		{DestinationName: "srl2", DestinationAddress: req.GetDestination(), Hops: 30, PacketSize: 60},
+		{Hop: 1, Address: "10.0.0.1", Rtt: int64(time.Millisecond)},
+		{Hop: 2, Address: req.GetDestination(), Rtt: int64(2 * time.Millisecond)},
+	}
+	for _, r := range resps {
+		if err := stream.Send(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Starts fake gNOI System server and returns client connected to it.
+func newFakeClient(t *testing.T, srv system.SystemServer) *Client {
+	t.Helper()
+	conn := sessiontest.NewConn(t, func(s *grpc.Server) { system.RegisterSystemServer(s, srv) })
+	return &Client{t: sessiontest.NewTarget(), sc: system.NewSystemClient(conn)}
+}
+
+func TestTime(t *testing.T) {
+	c := newFakeClient(t, new(fakeSystemServer))
+	tm, err := c.Time()
+	if err != nil {
+		t.Fatalf("got an error from Time(): %v", err)
+	}
+	if !tm.Equal(time.Unix(1_700_000_000, 0)) {
+		t.Errorf("incorrect time: %s", tm)
+	}
+}
+
+func TestReboot(t *testing.T) {
+	srv := new(fakeSystemServer)
+	c := newFakeClient(t, srv)
+	err := c.Reboot(RebootWarm, time.Minute, "maintenance")
+	if err != nil {
+		t.Fatalf("got an error from Reboot(): %v", err)
+	}
+	if srv.reboot.GetMethod() != system.RebootMethod_WARM || srv.reboot.GetDelay() != uint64(time.Minute) || srv.reboot.GetMessage() != "maintenance" {
+		t.Errorf("incorrect reboot request: %+v", srv.reboot)
+	}
+	if err := c.Reboot(RebootCold, -time.Second, ""); err == nil {
+		t.Errorf("expected error for negative delay")
+	}
+
+	st, err := c.RebootStatus()
+	if err != nil {
+		t.Fatalf("got an error from RebootStatus(): %v", err)
+	}
+	exp := &RebootStatus{Active: true, Wait: time.Minute, When: time.Unix(1_700_000_060, 0), Reason: "maintenance", Count: 3}
+	if diff := cmp.Diff(exp, st); diff != "" {
+		t.Errorf("RebootStatus() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSwitchControlProcessor(t *testing.T) {
+	c := newFakeClient(t, new(fakeSystemServer))
+	res, err := c.SwitchControlProcessor("B")
+	if err != nil {
+		t.Fatalf("got an error from SwitchControlProcessor(): %v", err)
+	}
+	exp := &SwitchoverResult{ControlProcessor: "B", Version: "v23.3.1", Uptime: time.Hour}
+	if diff := cmp.Diff(exp, res); diff != "" {
+		t.Errorf("SwitchControlProcessor() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPing(t *testing.T) {
+	c := newFakeClient(t, new(fakeSystemServer))
+	res, err := c.Ping("10.0.0.2", &PingOptions{Count: 3})
+	if err != nil {
+		t.Fatalf("got an error from Ping(): %v", err)
+	}
+	exp := &PingResult{
+		Destination: "10.0.0.2",
+		Replies: []PingReply{
+			{Source: "10.0.0.2", RTT: time.Millisecond, Bytes: 64, Sequence: 1, TTL: 64},
+			{Source: "10.0.0.2", RTT: 2 * time.Millisecond, Bytes: 64, Sequence: 2, TTL: 64},
+			{Source: "10.0.0.2", RTT: 3 * time.Millisecond, Bytes: 64, Sequence: 3, TTL: 64},
+		},
+		Sent:     3,
+		Received: 3,
+		MinRTT:   time.Millisecond,
+		AvgRTT:   2 * time.Millisecond,
+		MaxRTT:   3 * time.Millisecond,
+	}
+	if diff := cmp.Diff(exp, res); diff != "" {
+		t.Errorf("Ping() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTraceroute(t *testing.T) {
+	c := newFakeClient(t, new(fakeSystemServer))
+	res, err := c.Traceroute("10.0.0.2", nil)
+	if err != nil {
+		t.Fatalf("got an error from Traceroute(): %v", err)
+	}
+	exp := &TracerouteResult{
+		DestinationName:    "srl2",
+		DestinationAddress: "10.0.0.2",
+		MaxHops:            30,
+		PacketSize:         60,
+		Hops: []TracerouteHop{
+			{Hop: 1, Address: "10.0.0.1", RTT: time.Millisecond},
+			{Hop: 2, Address: "10.0.0.2", RTT: 2 * time.Millisecond},
+		},
+	}
+	if diff := cmp.Diff(exp, res); diff != "" {
+		t.Errorf("Traceroute() mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = c.Traceroute("10.0.0.2", &TracerouteOptions{L4Protocol: "sctp"})
+	if err == nil {
+		t.Errorf("expected error for unsupported L4 protocol")
+	}
+}
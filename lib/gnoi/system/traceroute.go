@@ -0,0 +1,139 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/session"
+	"github.com/openconfig/gnoi/system"
+)
+
+// TracerouteOptions are optional parameters of traceroute, zero values mean target defaults.
+type TracerouteOptions struct {
+	Source        string        // Source address to trace from.
+	InitialTTL    uint32        // TTL of the first probe.
+	MaxTTL        int32         // Maximum number of hops.
+	Wait          time.Duration // Time to wait for a response.
+	DoNotFragment bool          // Set DF bit.
+	DoNotResolve  bool          // Don't resolve addresses to names.
+	L4Protocol    string        // icmp, tcp or udp.
+}
+
+// TracerouteHop is the response to a single probe.
+type TracerouteHop struct {
+	Hop      int32
+	Address  string
+	Name     string
+	RTT      time.Duration
+	State    string            // ICMP state, e.g. HOST_UNREACHABLE, empty, if the probe got normal response.
+	MPLS     map[string]string // MPLS labels.
+	ASPath   []int32
+	ICMPCode int32
+}
+
+// TracerouteResult holds destination details sent by the target first and all hops received.
+type TracerouteResult struct {
+	DestinationName    string
+	DestinationAddress string
+	MaxHops            int32
+	PacketSize         int32
+	Hops               []TracerouteHop
+}
+
+// Traces route to destination from the target, opts could be nil.
+func Traceroute(t *lib.SRLTarget, dst string, opts *TracerouteOptions) (*TracerouteResult, error) {
+	var res *TracerouteResult
+	err := session.WithSession(t, *t.PortgNOI, func(s *session.Session) error {
+		c := NewClient(s)
+		var err error
+		res, err = c.Traceroute(dst, opts)
+		return err
+	})
+	return res, err
+}
+
+// Traces route to destination from the target, opts could be nil.
+// Target timeout is applied as inactivity timeout between responses.
+func (c *Client) Traceroute(dst string, opts *TracerouteOptions) (*TracerouteResult, error) {
+	if len(dst) == 0 {
+		return nil, fmt.Errorf("destination can't be null string")
+	}
+	if opts == nil {
+		opts = new(TracerouteOptions)
+	}
+	tReq := &system.TracerouteRequest{
+		Destination:   dst,
+		Source:        opts.Source,
+		InitialTtl:    opts.InitialTTL,
+		MaxTtl:        opts.MaxTTL,
+		Wait:          int64(opts.Wait),
+		DoNotFragment: opts.DoNotFragment,
+		DoNotResolve:  opts.DoNotResolve,
+	}
+	switch strings.ToLower(opts.L4Protocol) {
+	case "", "icmp":
+		tReq.L4Protocol = system.TracerouteRequest_ICMP
+	case "tcp":
+		tReq.L4Protocol = system.TracerouteRequest_TCP
+	case "udp":
+		tReq.L4Protocol = system.TracerouteRequest_UDP
+	default:
+		return nil, fmt.Errorf("unsupported L4 protocol: %q", opts.L4Protocol)
+	}
+
+	var res *TracerouteResult
+	// Traceroute has no side effects, so it's restarted from scratch on retry.
+	err := c.t.Retry.Do(session.Retryable, func() error {
+		// Creating context cancelled on inactivity.
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		idle := time.AfterFunc(*c.t.Timeout, cancel)
+		defer idle.Stop()
+
+		tStream, err := c.sc.Traceroute(ctx, tReq)
+		if err != nil {
+			return fmt.Errorf("can't exec Traceroute request: %w", err)
+		}
+
+		res = new(TracerouteResult)
+		for {
+			tResp, err := tStream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("can't get TracerouteResponse: %w", err)
+			}
+			idle.Reset(*c.t.Timeout)
+			// The first response describes destination rather than hop.
+			if tResp.GetHop() == 0 {
+				res.DestinationName = tResp.GetDestinationName()
+				res.DestinationAddress = tResp.GetDestinationAddress()
+				res.MaxHops = tResp.GetHops()
+				res.PacketSize = tResp.GetPacketSize()
+				continue
+			}
+			hop := TracerouteHop{
+				Hop:      tResp.GetHop(),
+				Address:  tResp.GetAddress(),
+				Name:     tResp.GetName(),
+				RTT:      time.Duration(tResp.GetRtt()),
+				MPLS:     tResp.GetMpls(),
+				ASPath:   tResp.GetAsPath(),
+				ICMPCode: tResp.GetIcmpCode(),
+			}
+			if tResp.GetState() != system.TracerouteResponse_DEFAULT {
+				hop.State = tResp.GetState().String()
+			}
+			res.Hops = append(res.Hops, hop)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -5,6 +5,12 @@ import (
 	"strings"
 )
 
+// Virtual root the info is wrapped into by NewInfoObject(), indexes of the tree are shifted by its header.
+const (
+	vRootHeader = "root {\n"
+	vRootFooter = "\n}\n"
+)
+
 type InfoObject struct {
 	Key     string
 	StLine  int
@@ -22,7 +28,7 @@ func NewInfoObject(info string) (*InfoObject, error) {
 		return nil, fmt.Errorf("malformed info; no blocks found")
 	}
 	// Creating virtual root.
-	rootedInfo := strings.Join([]string{"root {", info, "}\n"}, "\n")
+	rootedInfo := vRootHeader + info + vRootFooter
 
 	p, err := parseToInfoObjTree(rootedInfo, GetSubStrPositions(rootedInfo, "\n"), 0)
 	if err != nil {
@@ -190,11 +196,11 @@ func CleanUpClabInfoObjects(root *InfoObject, s string) (string, error) {
 		case "tls":
 			for _, c := range c.Chlds {
 				if c.Key == "server-profile clab-profile" {
-					sliceInx = append(sliceInx, c.StInd, c.EndInd)
+					sliceInx = append(sliceInx, blockRange(c, s)...)
 				}
 			}
 		case "gnmi-server", "json-rpc-server", "banner":
-			sliceInx = append(sliceInx, c.StInd, c.EndInd)
+			sliceInx = append(sliceInx, blockRange(c, s)...)
 		}
 	}
 	sliceInx = append(sliceInx, len(s))
@@ -206,6 +212,144 @@ func CleanUpClabInfoObjects(root *InfoObject, s string) (string, error) {
 	return strings.Join(sanStr, ""), nil
 }
 
+// Returns lines of the block in the info, the tree is created from by NewInfoObject(), w/o eol of the last line.
+func (o *InfoObject) Block(info string) string {
+	r := blockRange(o, info)
+	return strings.TrimSuffix(info[r[0]:r[1]], "\n")
+}
+
+// Function returns start and end indexes of the block lines in the info s under virtual root, incl. eol of the last line.
+func blockRange(c *InfoObject, s string) []int {
+	end := c.EndInd - len(vRootHeader) + 1
+	if end > len(s) {
+		// The last line of the info w/o eol.
+		end = len(s)
+	}
+	return []int{c.StInd - len(vRootHeader), end}
+}
+
+// Function is removing interfaces clab enables for the endpoints of the node, e.g. ethernet-1/1, from the info tree.
+// Only interfaces with nothing but "admin-state enable" are removed, clab enables them again on deploy.
+func CleanUpClabEndpoints(root *InfoObject, s string, endpoints []string) (string, error) {
+	var sliceInx = make([]int, 1, 10)
+	var sanStr []string
+
+	// Protection from being provided non root.
+	if root.Key != "root" {
+		return "", fmt.Errorf("root InfoObject should be with virtual root")
+	}
+
+	eps := make(map[string]bool)
+	for _, ep := range endpoints {
+		eps["interface "+ep] = true
+	}
+	for _, c := range root.Chlds {
+		if !eps[c.Key] || len(c.Chlds) != 0 {
+			continue
+		}
+		r := blockRange(c, s)
+		block := strings.Split(strings.TrimSpace(s[r[0]:r[1]]), "\n")
+		if len(block) == 3 && strings.TrimSpace(block[1]) == "admin-state enable" {
+			sliceInx = append(sliceInx, r...)
+		}
+	}
+	sliceInx = append(sliceInx, len(s))
+
+	for i := 0; i < len(sliceInx); i += 2 {
+		sanStr = append(sanStr, s[sliceInx[i]:sliceInx[i+1]])
+	}
+
+	return strings.Join(sanStr, ""), nil
+}
+
+// Function is removing the same clab related config as CleanUpClabInfoObjects() from JSON config tree, e.g. received via gNMI.
+// Module prefixes of the elements, e.g. srl_nokia-system:system, are ignored.
+func CleanUpClabJSON(cfg map[string]any) error {
+	v, _ := JSONChild(cfg, "system")
+	system, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("unable to find system elem in tree")
+	}
+	for k, v := range system {
+		switch jsonName(k) {
+		case "tls":
+			tls, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			for k, v := range tls {
+				if jsonName(k) != "server-profile" {
+					continue
+				}
+				profiles, ok := v.([]any)
+				if !ok {
+					continue
+				}
+				var kept []any
+				for _, p := range profiles {
+					if pm, ok := p.(map[string]any); ok && pm["name"] == "clab-profile" {
+						continue
+					}
+					kept = append(kept, p)
+				}
+				tls[k] = kept
+				if len(kept) == 0 {
+					delete(tls, k)
+				}
+			}
+		case "gnmi-server", "json-rpc-server", "banner":
+			delete(system, k)
+		}
+	}
+	return nil
+}
+
+// Function is removing the same endpoint interfaces as CleanUpClabEndpoints() from JSON config tree.
+func CleanUpClabEndpointsJSON(cfg map[string]any, endpoints []string) {
+	eps := make(map[string]bool)
+	for _, ep := range endpoints {
+		eps[ep] = true
+	}
+	for k, v := range cfg {
+		if jsonName(k) != "interface" {
+			continue
+		}
+		ifs, ok := v.([]any)
+		if !ok {
+			continue
+		}
+		var kept []any
+		for _, i := range ifs {
+			im, ok := i.(map[string]any)
+			if ok && len(im) == 2 && eps[fmt.Sprint(im["name"])] {
+				if as, _ := JSONChild(im, "admin-state"); as == "enable" {
+					continue
+				}
+			}
+			kept = append(kept, i)
+		}
+		cfg[k] = kept
+		if len(kept) == 0 {
+			delete(cfg, k)
+		}
+	}
+}
+
+// Returns child element of JSON object by name w/o module prefix, e.g. system for srl_nokia-system:system.
+func JSONChild(m map[string]any, name string) (any, bool) {
+	for k, v := range m {
+		if jsonName(k) == name {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Function returns name of JSON element w/o module prefix.
+func jsonName(k string) string {
+	return k[strings.LastIndex(k, ":")+1:]
+}
+
 // Func prints object tree to the terminal.
 func PrintInfObjTree(i *InfoObject, ident ...int) {
 	if len(ident) == 0 {
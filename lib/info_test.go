@@ -171,3 +171,101 @@ func getKeysOfInfObjLvl(i *lib.InfoObject, lvl int) []string {
 	}
 	return keys
 }
+
+func TestCleanUpClabJSON(t *testing.T) {
+	cfg := map[string]any{
+		"srl_nokia-system:system": map[string]any{
+			"srl_nokia-lldp:lldp": map[string]any{"admin-state": "enable"},
+			"srl_nokia-tls:tls": map[string]any{
+				"server-profile": []any{
+					map[string]any{"name": "clab-profile", "authenticate-client": false},
+					map[string]any{"name": "mgmt", "authenticate-client": true},
+				},
+			},
+			"srl_nokia-gnmi-server:gnmi-server":  map[string]any{"admin-state": "enable"},
+			"srl_nokia-json-rpc:json-rpc-server": map[string]any{"admin-state": "enable"},
+			"srl_nokia-system-banner:banner":     map[string]any{"login-banner": "clab"},
+		},
+	}
+	err := lib.CleanUpClabJSON(cfg)
+	if err != nil {
+		t.Fatalf("got an error from CleanUpClabJSON(): %+v\n", err)
+	}
+	want := map[string]any{
+		"srl_nokia-system:system": map[string]any{
+			"srl_nokia-lldp:lldp": map[string]any{"admin-state": "enable"},
+			"srl_nokia-tls:tls": map[string]any{
+				"server-profile": []any{
+					map[string]any{"name": "mgmt", "authenticate-client": true},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, cfg); diff != "" {
+		t.Errorf("CleanUpClabJSON() mismatch (-want +got):\n%s", diff)
+	}
+
+	err = lib.CleanUpClabJSON(map[string]any{"srl_nokia-interfaces:interface": []any{}})
+	if err == nil || err.Error() != "unable to find system elem in tree" {
+		t.Errorf("expected missed system error; got: %v", err)
+	}
+}
+
+func TestCleanUpClabEndpoints(t *testing.T) {
+	info := `system {
+    name {
+        host-name leaf1
+    }
+    banner {
+        login-banner clab
+    }
+}
+interface ethernet-1/1 {
+    admin-state enable
+}
+interface ethernet-1/2 {
+    admin-state enable
+    subinterface 0 {
+    }
+}
+interface ethernet-1/3 {
+    admin-state enable
+}`
+	e := &lib.Extraction{Format: lib.ConfigFormatInfo, Config: info}
+	err := e.CleanUpClab("ethernet-1/1", "ethernet-1/2", "ethernet-1/3")
+	if err != nil {
+		t.Fatalf("got an error from CleanUpClab(): %+v\n", err)
+	}
+	want := `system {
+    name {
+        host-name leaf1
+    }
+}
+interface ethernet-1/2 {
+    admin-state enable
+    subinterface 0 {
+    }
+}
+`
+	if diff := cmp.Diff(want, e.Config); diff != "" {
+		t.Errorf("CleanUpClab() mismatch (-want +got):\n%s", diff)
+	}
+
+	cfg := map[string]any{
+		"srl_nokia-interfaces:interface": []any{
+			map[string]any{"name": "ethernet-1/1", "admin-state": "enable"},
+			map[string]any{"name": "ethernet-1/2", "admin-state": "enable", "description": "to spine1"},
+			map[string]any{"name": "ethernet-1/3", "admin-state": "enable"},
+		},
+	}
+	lib.CleanUpClabEndpointsJSON(cfg, []string{"ethernet-1/1", "ethernet-1/2"})
+	wantJSON := map[string]any{
+		"srl_nokia-interfaces:interface": []any{
+			map[string]any{"name": "ethernet-1/2", "admin-state": "enable", "description": "to spine1"},
+			map[string]any{"name": "ethernet-1/3", "admin-state": "enable"},
+		},
+	}
+	if diff := cmp.Diff(wantJSON, cfg); diff != "" {
+		t.Errorf("CleanUpClabEndpointsJSON() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,366 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/azyablov/fat/lib"
+	"gopkg.in/yaml.v3"
+)
+
+// Default credentials of SR Linux nodes deployed by containerlab.
+const (
+	ClabUsername = "admin"
+	ClabPassword = "NokiaSrl1!"
+)
+
+// Kinds of SR Linux nodes in containerlab topology.
+var clabKinds = map[string]bool{"srl": true, "nokia_srlinux": true}
+
+// Containerlab topology file, e.g. topology.clab.yml.
+type clabTopology struct {
+	Name     string  `yaml:"name"`
+	Prefix   *string `yaml:"prefix"` // container name prefix, "clab" if missed
+	Topology struct {
+		Defaults clabNode            `yaml:"defaults"`
+		Groups   map[string]clabNode `yaml:"groups"`
+		Nodes    map[string]clabNode `yaml:"nodes"`
+		Links    []struct {
+			Endpoints []clabEndpoint `yaml:"endpoints"`
+			Endpoint  *clabEndpoint  `yaml:"endpoint"` // single endpoint links, e.g. mgmt-net or host
+		} `yaml:"links"`
+	} `yaml:"topology"`
+}
+
+// Node of containerlab topology, attributes missed are taken from the group and defaults.
+type clabNode struct {
+	Kind     string `yaml:"kind"`
+	Group    string `yaml:"group"`
+	MgmtIPv4 string `yaml:"mgmt-ipv4"`
+}
+
+// Link endpoint, either "node:interface" or mapping with node and interface.
+type clabEndpoint struct {
+	Node      string `yaml:"node" json:"node"`
+	Interface string `yaml:"interface" json:"interface"`
+}
+
+func (ep *clabEndpoint) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.ScalarNode {
+		type plain clabEndpoint
+		return n.Decode((*plain)(ep))
+	}
+	var ok bool
+	ep.Node, ep.Interface, ok = strings.Cut(n.Value, ":")
+	if !ok {
+		return fmt.Errorf("incorrect endpoint %q, expected node:interface", n.Value)
+	}
+	return nil
+}
+
+// topology-data.json containerlab writes into the lab directory.
+type clabData struct {
+	Name  string `json:"name"`
+	Nodes map[string]struct {
+		LongName string `json:"longname"`
+		Kind     string `json:"kind"`
+		MgmtIPv4 string `json:"mgmt-ipv4-address"`
+	} `json:"nodes"`
+	Links []struct {
+		A         *clabEndpoint `json:"a"`
+		Z         *clabEndpoint `json:"z"`
+		Endpoints *struct {
+			A *clabEndpoint `json:"a"`
+			Z *clabEndpoint `json:"z"`
+		} `json:"endpoints"` // containerlab v0.48 and later
+	} `json:"links"`
+}
+
+// Returns true, if YAML document is containerlab topology.
+func isClabTopology(n *yaml.Node) bool {
+	return mappingValue(n, "topology") != nil
+}
+
+// Reads SR Linux nodes of containerlab topology file, e.g. topology.clab.yml, as hosts.
+// Nodes are addressed by mgmt-ipv4, if it's set, or by container name otherwise, clab puts it into /etc/hosts.
+func ReadClabTopology(r io.Reader) ([]*Host, error) {
+	var ct clabTopology
+	err := yaml.NewDecoder(r).Decode(&ct)
+	if err != nil {
+		return nil, err
+	}
+	return ct.hosts()
+}
+
+// Function returns hosts of SR Linux nodes of the topology.
+func (ct *clabTopology) hosts() ([]*Host, error) {
+	if ct.Name == "" {
+		return nil, fmt.Errorf("lab name is missed")
+	}
+	prefix := "clab-" + ct.Name + "-"
+	if ct.Prefix != nil {
+		switch *ct.Prefix {
+		case "":
+			prefix = ""
+		case "__lab-name":
+			prefix = ct.Name + "-"
+		default:
+			prefix = *ct.Prefix + "-" + ct.Name + "-"
+		}
+	}
+
+	var eps []*clabEndpoint
+	for _, l := range ct.Topology.Links {
+		for i := range l.Endpoints {
+			eps = append(eps, &l.Endpoints[i])
+		}
+		if l.Endpoint != nil {
+			eps = append(eps, l.Endpoint)
+		}
+	}
+
+	hs := make([]*Host, 0, len(ct.Topology.Nodes))
+	for name, n := range ct.Topology.Nodes {
+		kind := n.Kind
+		if kind == "" {
+			kind = ct.Topology.Groups[n.Group].Kind
+		}
+		if kind == "" {
+			kind = ct.Topology.Defaults.Kind
+		}
+		if !clabKinds[kind] {
+			continue
+		}
+		h := clabHost(name, eps)
+		h.Hostname = n.MgmtIPv4
+		if h.Hostname == "" {
+			h.Hostname = prefix + name
+		}
+		hs = append(hs, h)
+	}
+	return sortedClabHosts(hs)
+}
+
+// Reads SR Linux nodes of topology-data.json containerlab writes into the lab directory as hosts.
+// Nodes are addressed by mgmt IPv4 address, or by container name, if the address isn't assigned.
+func ReadClabData(r io.Reader) ([]*Host, error) {
+	var cd clabData
+	err := json.NewDecoder(r).Decode(&cd)
+	if err != nil {
+		return nil, err
+	}
+
+	var eps []*clabEndpoint
+	for _, l := range cd.Links {
+		if l.Endpoints != nil {
+			eps = append(eps, l.Endpoints.A, l.Endpoints.Z)
+		}
+		eps = append(eps, l.A, l.Z)
+	}
+
+	hs := make([]*Host, 0, len(cd.Nodes))
+	for name, n := range cd.Nodes {
+		if !clabKinds[n.Kind] {
+			continue
+		}
+		h := clabHost(name, eps)
+		h.Hostname = n.MgmtIPv4
+		if h.Hostname == "" {
+			h.Hostname = n.LongName
+		}
+		hs = append(hs, h)
+	}
+	return sortedClabHosts(hs)
+}
+
+// Function returns host of the node with clab credentials and interfaces of the node referenced by the endpoints.
+func clabHost(node string, eps []*clabEndpoint) *Host {
+	h := &Host{Node: node, Username: ClabUsername, Password: ClabPassword}
+	seen := make(map[string]bool)
+	for _, ep := range eps {
+		if ep == nil || ep.Node != node {
+			continue
+		}
+		i, ok := ClabInterface(ep.Interface)
+		if !ok || seen[i] {
+			continue
+		}
+		seen[i] = true
+		h.Endpoints = append(h.Endpoints, i)
+	}
+	sort.Strings(h.Endpoints)
+	return h
+}
+
+// Function sorts hosts by node name and checks them.
+func sortedClabHosts(hs []*Host) ([]*Host, error) {
+	if len(hs) == 0 {
+		return nil, fmt.Errorf("no SR Linux nodes in the topology")
+	}
+	sort.Slice(hs, func(i, j int) bool {
+		return hs[i].Node < hs[j].Node
+	})
+	return hs, check(hs)
+}
+
+// Returns SR Linux name of the interface referenced by clab endpoint, e.g. ethernet-1/1 for e1-1
+// and ethernet-1/3/1 for e1-3-1. Returns false for non ethernet interfaces, e.g. mgmt0.
+func ClabInterface(name string) (string, bool) {
+	if strings.HasPrefix(name, "ethernet-") {
+		return name, true
+	}
+	if !strings.HasPrefix(name, "e") {
+		return "", false
+	}
+	parts := strings.Split(name[1:], "-")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", false
+	}
+	for _, p := range parts {
+		if p == "" || strings.Trim(p, "0123456789") != "" {
+			return "", false
+		}
+	}
+	return "ethernet-" + strings.Join(parts, "/"), true
+}
+
+// Returns startup-config file of the node relative to the lab directory, e.g. configs/leaf1.cli.
+// clab picks the way the config is applied by extension: .cli file is executed as CLI commands on top of the default config,
+// so info config goes there, while JSON one is loaded as the whole config.
+func ClabStartupConfig(node string, format string) string {
+	ext := "cli"
+	if format == lib.ConfigFormatJSON {
+		ext = "json"
+	}
+	return "configs/" + node + "." + ext
+}
+
+// Writes containerlab topology read from r into w with startup-config of the nodes set to their config files,
+// e.g. configs/leaf1.cli. Comments and the rest of the topology are kept, nodes missed in configs aren't changed.
+// Relative paths of the nodes, groups, kinds and defaults (license, binds and startup-config) are rewritten
+// from srcDir the topology is read from to dstDir it's written into, as clab resolves them against the topology directory.
+func PatchClabTopology(r io.Reader, w io.Writer, configs map[string]string, srcDir string, dstDir string) error {
+	var doc yaml.Node
+	err := yaml.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return fmt.Errorf("can't read topology: %w", err)
+	}
+	if len(doc.Content) == 0 || !isClabTopology(doc.Content[0]) {
+		return fmt.Errorf("not a containerlab topology")
+	}
+	topo := mappingValue(doc.Content[0], "topology")
+	nodes := mappingValue(topo, "nodes")
+	if nodes == nil || nodes.Kind != yaml.MappingNode {
+		return fmt.Errorf("no nodes in the topology")
+	}
+
+	rebase := func(p string) (string, error) {
+		return rebasePath(p, srcDir, dstDir)
+	}
+	err = rebaseNodePaths(mappingValue(topo, "defaults"), rebase)
+	for _, section := range []string{"kinds", "groups", "nodes"} {
+		s := mappingValue(topo, section)
+		if s == nil || s.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 1; i < len(s.Content) && err == nil; i += 2 {
+			err = rebaseNodePaths(s.Content[i], rebase)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	patched := 0
+	for i := 0; i < len(nodes.Content); i += 2 {
+		cfg, ok := configs[nodes.Content[i].Value]
+		if !ok {
+			continue
+		}
+		n := nodes.Content[i+1]
+		if n.Kind != yaml.MappingNode {
+			// Node w/o attributes, e.g. "leaf1:".
+			*n = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		v := mappingValue(n, "startup-config")
+		if v == nil {
+			v = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "startup-config"}, v)
+		}
+		v.Value = cfg
+		patched++
+	}
+	if patched != len(configs) {
+		return fmt.Errorf("%d of %d nodes are missed in the topology", len(configs)-patched, len(configs))
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	err = enc.Encode(&doc)
+	if err != nil {
+		return fmt.Errorf("can't write topology: %w", err)
+	}
+	return enc.Close()
+}
+
+// Function rewrites relative paths of node attributes: license, host part of binds and startup-config.
+func rebaseNodePaths(n *yaml.Node, rebase func(string) (string, error)) error {
+	for _, key := range []string{"license", "startup-config"} {
+		v := mappingValue(n, key)
+		if v == nil || v.Kind != yaml.ScalarNode {
+			continue
+		}
+		p, err := rebase(v.Value)
+		if err != nil {
+			return err
+		}
+		v.Value = p
+	}
+	binds := mappingValue(n, "binds")
+	if binds == nil || binds.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for _, v := range binds.Content {
+		// host-path:container-path[:options]
+		host, rest, ok := strings.Cut(v.Value, ":")
+		if v.Kind != yaml.ScalarNode || !ok {
+			continue
+		}
+		p, err := rebase(host)
+		if err != nil {
+			return err
+		}
+		v.Value = p + ":" + rest
+	}
+	return nil
+}
+
+// Function rewrites path relative to srcDir into one relative to dstDir. Absolute paths, home directory and clab magic
+// variables, e.g. __clabNodeDir__, are kept as is.
+func rebasePath(p string, srcDir string, dstDir string) (string, error) {
+	if p == "" || filepath.IsAbs(p) || strings.HasPrefix(p, "~") || strings.HasPrefix(p, "$") || strings.HasPrefix(p, "__clab") {
+		return p, nil
+	}
+	rel, err := filepath.Rel(dstDir, filepath.Join(srcDir, p))
+	if err != nil {
+		return "", fmt.Errorf("can't rewrite path %s: %w", p, err)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Function returns value of the key in YAML mapping, nil if it's missed.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
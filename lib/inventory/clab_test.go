@@ -0,0 +1,203 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadClabTopology(t *testing.T) {
+	topo := `
+name: evpn
+topology:
+  defaults:
+    kind: nokia_srlinux
+  nodes:
+    leaf1:
+      mgmt-ipv4: 172.20.20.11
+    spine1:
+      kind: srl
+    client1:
+      kind: linux
+  links:
+    - endpoints: ["leaf1:e1-1", "spine1:e1-1"]
+    - endpoints: ["leaf1:e1-3-1", "client1:eth1"]
+    - endpoints:
+        - node: spine1
+          interface: ethernet-1/2
+        - node: client1
+          interface: eth2
+    - type: mgmt-net
+      endpoint:
+        node: leaf1
+        interface: mgmt0
+`
+	hs, err := ReadYAML(strings.NewReader(topo))
+	if err != nil {
+		t.Fatalf("got an error from ReadYAML(): %v", err)
+	}
+	want := []*Host{
+		{Hostname: "172.20.20.11", Username: ClabUsername, Password: ClabPassword, Node: "leaf1", Endpoints: []string{"ethernet-1/1", "ethernet-1/3/1"}},
+		{Hostname: "clab-evpn-spine1", Username: ClabUsername, Password: ClabPassword, Node: "spine1", Endpoints: []string{"ethernet-1/1", "ethernet-1/2"}},
+	}
+	if diff := cmp.Diff(want, hs); diff != "" {
+		t.Errorf("ReadYAML() mismatch (-want +got):\n%s", diff)
+	}
+
+	hs, err = ReadClabTopology(strings.NewReader("name: evpn\nprefix: __lab-name\ntopology:\n  nodes:\n    leaf1:\n      kind: srl\n"))
+	if err != nil || len(hs) != 1 || hs[0].Hostname != "evpn-leaf1" {
+		t.Errorf("expected evpn-leaf1 host; got: %v, %v", hs, err)
+	}
+	if _, err := ReadClabTopology(strings.NewReader("name: evpn\ntopology:\n  nodes:\n    client1:\n      kind: linux\n")); err == nil {
+		t.Errorf("expected an error for topology w/o SR Linux nodes")
+	}
+}
+
+func TestReadClabData(t *testing.T) {
+	data := `{
+  "name": "evpn",
+  "type": "clab",
+  "nodes": {
+    "leaf1": {"shortname": "leaf1", "longname": "clab-evpn-leaf1", "kind": "nokia_srlinux", "mgmt-ipv4-address": "172.20.20.11"},
+    "spine1": {"shortname": "spine1", "longname": "clab-evpn-spine1", "kind": "srl", "mgmt-ipv4-address": ""},
+    "client1": {"shortname": "client1", "longname": "clab-evpn-client1", "kind": "linux", "mgmt-ipv4-address": "172.20.20.21"}
+  },
+  "links": [
+    {"endpoints": {"a": {"node": "leaf1", "interface": "e1-1"}, "z": {"node": "spine1", "interface": "e1-1"}}},
+    {"a": {"node": "leaf1", "interface": "e1-2"}, "z": {"node": "client1", "interface": "eth1"}}
+  ]
+}`
+	hs, err := ReadClabData(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("got an error from ReadClabData(): %v", err)
+	}
+	want := []*Host{
+		{Hostname: "172.20.20.11", Username: ClabUsername, Password: ClabPassword, Node: "leaf1", Endpoints: []string{"ethernet-1/1", "ethernet-1/2"}},
+		{Hostname: "clab-evpn-spine1", Username: ClabUsername, Password: ClabPassword, Node: "spine1", Endpoints: []string{"ethernet-1/1"}},
+	}
+	if diff := cmp.Diff(want, hs); diff != "" {
+		t.Errorf("ReadClabData() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestClabInterface(t *testing.T) {
+	testData := map[string]string{
+		"e1-1":          "ethernet-1/1",
+		"e1-3-1":        "ethernet-1/3/1",
+		"ethernet-1/10": "ethernet-1/10",
+		"mgmt0":         "",
+		"eth1":          "",
+		"e1":            "",
+	}
+	for in, want := range testData {
+		got, ok := ClabInterface(in)
+		if got != want || ok != (want != "") {
+			t.Errorf("ClabInterface(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+}
+
+func TestPatchClabTopology(t *testing.T) {
+	topo := `name: evpn # lab name
+topology:
+  nodes:
+    leaf1:
+      kind: srl
+      startup-config: leaf1.cli
+    spine1:
+    client1:
+      kind: linux
+  links:
+    - endpoints: ["leaf1:e1-1", "spine1:e1-1"]
+`
+	var b strings.Builder
+	err := PatchClabTopology(strings.NewReader(topo), &b, map[string]string{
+		"leaf1":  ClabStartupConfig("leaf1", lib.ConfigFormatInfo),
+		"spine1": ClabStartupConfig("spine1", lib.ConfigFormatJSON),
+	}, ".", ".")
+	if err != nil {
+		t.Fatalf("got an error from PatchClabTopology(): %v", err)
+	}
+	want := `name: evpn # lab name
+topology:
+  nodes:
+    leaf1:
+      kind: srl
+      startup-config: configs/leaf1.cli
+    spine1:
+      startup-config: configs/spine1.json
+    client1:
+      kind: linux
+  links:
+    - endpoints: ["leaf1:e1-1", "spine1:e1-1"]
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("PatchClabTopology() mismatch (-want +got):\n%s", diff)
+	}
+
+	err = PatchClabTopology(strings.NewReader(topo), &b, map[string]string{"leaf2": "configs/leaf2.cli"}, ".", ".")
+	if err == nil {
+		t.Errorf("expected an error for node missed in the topology")
+	}
+}
+
+func TestPatchClabTopologyRelativePaths(t *testing.T) {
+	topo := `name: evpn
+topology:
+  defaults:
+    binds:
+      - /etc/hosts:/etc/hosts:ro
+  kinds:
+    srl:
+      license: license.key
+  nodes:
+    leaf1:
+      kind: srl
+      startup-config: leaf1.cli
+      binds:
+        - scripts:/opt/scripts
+        - __clabNodeDir__/tmp:/tmp
+    client1:
+      kind: linux
+      startup-config: ../shared/client.sh
+      license: ~/license.key
+      binds:
+        - ./data:/data:ro
+        - /var/run/docker.sock:/var/run/docker.sock
+`
+	var b strings.Builder
+	err := PatchClabTopology(strings.NewReader(topo), &b, map[string]string{
+		"leaf1": ClabStartupConfig("leaf1", lib.ConfigFormatInfo),
+	}, "lab", "lab/snapshot")
+	if err != nil {
+		t.Fatalf("got an error from PatchClabTopology(): %v", err)
+	}
+	want := `name: evpn
+topology:
+  defaults:
+    binds:
+      - /etc/hosts:/etc/hosts:ro
+  kinds:
+    srl:
+      license: ../license.key
+  nodes:
+    leaf1:
+      kind: srl
+      startup-config: configs/leaf1.cli
+      binds:
+        - ../scripts:/opt/scripts
+        - __clabNodeDir__/tmp:/tmp
+    client1:
+      kind: linux
+      startup-config: ../../shared/client.sh
+      license: ~/license.key
+      binds:
+        - ../data:/data:ro
+        - /var/run/docker.sock:/var/run/docker.sock
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("PatchClabTopology() mismatch (-want +got):\n%s", diff)
+	}
+}
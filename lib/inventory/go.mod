@@ -0,0 +1,11 @@
+module github.com/azyablov/fat/lib/inventory
+
+go 1.18
+
+require (
+	github.com/azyablov/fat/lib v0.0.0-20230317114747-14ecb7a2410e
+	github.com/google/go-cmp v0.5.9
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/azyablov/fat/lib => ../
@@ -0,0 +1,359 @@
+// Package inventory loads the list of targets from YAML or CSV file, containerlab topology, or expands it from the host pattern.
+package inventory
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"gopkg.in/yaml.v3"
+)
+
+// Host is the inventory entry, zero fields are taken from the defaults.
+type Host struct {
+	Hostname   string        `yaml:"hostname"`
+	Username   string        `yaml:"username,omitempty"`
+	Password   string        `yaml:"password,omitempty"`
+	PortSSH    int           `yaml:"ssh-port,omitempty"`
+	PortJRpc   int           `yaml:"jrpc-port,omitempty"`
+	PortgNOI   int           `yaml:"gnoi-port,omitempty"`
+	PortgNMI   int           `yaml:"gnmi-port,omitempty"`
+	Timeout    time.Duration `yaml:"timeout,omitempty"`
+	Transports []string      `yaml:"transports,omitempty"` // transports tried in order, e.g. gnoi, jsonrpc, ssh
+
+	// Set for containerlab nodes only.
+	Node      string   `yaml:"-"` // node name in the topology
+	Endpoints []string `yaml:"-"` // interfaces referenced by the links, e.g. ethernet-1/1
+}
+
+// YAML inventory, hosts could be provided as top level list as well.
+type file struct {
+	Defaults Host   `yaml:"defaults"`
+	Hosts    []Host `yaml:"hosts"`
+}
+
+// Loads hosts from YAML (.yml, .yaml) or CSV (.csv) file, or SR Linux nodes of containerlab topology:
+// topology file, e.g. topology.clab.yml, or topology-data.json (.json) of the lab directory.
+func Load(fName string) ([]*Host, error) {
+	fh, err := os.Open(fName)
+	if err != nil {
+		return nil, fmt.Errorf("can't open inventory: %s", err)
+	}
+	defer fh.Close()
+
+	var hs []*Host
+	switch strings.ToLower(filepath.Ext(fName)) {
+	case ".yml", ".yaml":
+		hs, err = ReadYAML(fh)
+	case ".csv":
+		hs, err = ReadCSV(fh)
+	case ".json":
+		hs, err = ReadClabData(fh)
+	default:
+		return nil, fmt.Errorf("unknown inventory format %s, expected .yml, .yaml, .csv or .json", filepath.Ext(fName))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read inventory %s: %w", fName, err)
+	}
+	return hs, nil
+}
+
+// Reads YAML inventory: either list of hosts or mapping with hosts list and defaults applied to every host.
+// Containerlab topology is read by ReadClabTopology().
+func ReadYAML(r io.Reader) ([]*Host, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var n yaml.Node
+	err = yaml.Unmarshal(b, &n)
+	if err != nil {
+		return nil, err
+	}
+	if len(n.Content) == 0 {
+		return nil, fmt.Errorf("empty inventory")
+	}
+
+	if isClabTopology(n.Content[0]) {
+		var ct clabTopology
+		err = n.Content[0].Decode(&ct)
+		if err != nil {
+			return nil, err
+		}
+		return ct.hosts()
+	}
+
+	var f file
+	if n.Content[0].Kind == yaml.SequenceNode {
+		err = n.Content[0].Decode(&f.Hosts)
+	} else {
+		err = n.Content[0].Decode(&f)
+	}
+	if err != nil {
+		return nil, err
+	}
+	hs := make([]*Host, 0, len(f.Hosts))
+	for i := range f.Hosts {
+		h := f.Hosts[i]
+		h.merge(&f.Defaults)
+		hs = append(hs, &h)
+	}
+	return hs, check(hs)
+}
+
+// Reads CSV inventory, the first row is the header with column names as YAML keys, e.g. hostname,username,password.
+// Transports are separated by spaces or semicolons.
+func ReadCSV(r io.Reader) ([]*Host, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("empty inventory")
+	}
+
+	header := rows[0]
+	var hs []*Host
+	for i, row := range rows[1:] {
+		h := new(Host)
+		for j, v := range row {
+			if v == "" {
+				continue
+			}
+			err := h.set(strings.TrimSpace(header[j]), v)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+2, err)
+			}
+		}
+		hs = append(hs, h)
+	}
+	return hs, check(hs)
+}
+
+// Function sets the field of the host by its YAML key.
+func (h *Host) set(key string, v string) error {
+	var err error
+	switch key {
+	case "hostname":
+		h.Hostname = v
+	case "username":
+		h.Username = v
+	case "password":
+		h.Password = v
+	case "ssh-port":
+		h.PortSSH, err = strconv.Atoi(v)
+	case "jrpc-port":
+		h.PortJRpc, err = strconv.Atoi(v)
+	case "gnoi-port":
+		h.PortgNOI, err = strconv.Atoi(v)
+	case "gnmi-port":
+		h.PortgNMI, err = strconv.Atoi(v)
+	case "timeout":
+		h.Timeout, err = time.ParseDuration(v)
+	case "transports":
+		h.Transports = strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ';' })
+	default:
+		return fmt.Errorf("unknown column %s", key)
+	}
+	if err != nil {
+		return fmt.Errorf("incorrect %s %q: %s", key, v, err)
+	}
+	return nil
+}
+
+// Function sets zero fields of the host from defaults.
+func (h *Host) merge(d *Host) {
+	if h.Username == "" {
+		h.Username = d.Username
+	}
+	if h.Password == "" {
+		h.Password = d.Password
+	}
+	if h.PortSSH == 0 {
+		h.PortSSH = d.PortSSH
+	}
+	if h.PortJRpc == 0 {
+		h.PortJRpc = d.PortJRpc
+	}
+	if h.PortgNOI == 0 {
+		h.PortgNOI = d.PortgNOI
+	}
+	if h.PortgNMI == 0 {
+		h.PortgNMI = d.PortgNMI
+	}
+	if h.Timeout == 0 {
+		h.Timeout = d.Timeout
+	}
+	if len(h.Transports) == 0 {
+		h.Transports = d.Transports
+	}
+}
+
+// Function checks hostnames are present and unique.
+func check(hs []*Host) error {
+	seen := make(map[string]bool)
+	for i, h := range hs {
+		if h.Hostname == "" {
+			return fmt.Errorf("hostname of host %d is missed", i+1)
+		}
+		if seen[h.Hostname] {
+			return fmt.Errorf("host %s is duplicated", h.Hostname)
+		}
+		seen[h.Hostname] = true
+	}
+	return nil
+}
+
+// Returns hosts of comma separated list of patterns, where {a,b} gives alternatives and {1..4} numeric range,
+// e.g. leaf{1..3},spine{1,2} gives leaf1, leaf2, leaf3, spine1 and spine2. Zero padded ranges, e.g. {01..12}, keep width.
+func Expand(patterns string) ([]*Host, error) {
+	var hs []*Host
+	for _, p := range splitTop(patterns) {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		names, err := expand(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range names {
+			hs = append(hs, &Host{Hostname: n})
+		}
+	}
+	if len(hs) == 0 {
+		return nil, fmt.Errorf("no hosts in %q", patterns)
+	}
+	return hs, check(hs)
+}
+
+// Function splits patterns by commas outside of braces.
+func splitTop(s string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i, r := range s {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// Function expands the first brace group of the pattern and the rest of it recursively.
+func expand(p string) ([]string, error) {
+	open := strings.Index(p, "{")
+	if open == -1 {
+		if strings.Contains(p, "}") {
+			return nil, fmt.Errorf("unbalanced braces in %q", p)
+		}
+		return []string{p}, nil
+	}
+	depth := 0
+	closing := -1
+	for i := open; i < len(p); i++ {
+		if p[i] == '{' {
+			depth++
+		}
+		if p[i] == '}' {
+			depth--
+			if depth == 0 {
+				closing = i
+				break
+			}
+		}
+	}
+	if closing == -1 {
+		return nil, fmt.Errorf("unbalanced braces in %q", p)
+	}
+
+	alts, err := alternatives(p[open+1 : closing])
+	if err != nil {
+		return nil, fmt.Errorf("incorrect pattern %q: %s", p, err)
+	}
+	var names []string
+	for _, a := range alts {
+		ns, err := expand(p[:open] + a + p[closing+1:])
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, ns...)
+	}
+	return names, nil
+}
+
+// Function returns alternatives of the brace group: numeric range or comma separated list.
+func alternatives(g string) ([]string, error) {
+	from, to, ok := strings.Cut(g, "..")
+	if !ok {
+		return splitTop(g), nil
+	}
+	f, err := strconv.Atoi(from)
+	if err != nil {
+		return nil, err
+	}
+	t, err := strconv.Atoi(to)
+	if err != nil {
+		return nil, err
+	}
+	if f > t {
+		return nil, fmt.Errorf("range %s is reversed", g)
+	}
+	width := 0
+	if strings.HasPrefix(from, "0") && len(from) > 1 {
+		width = len(from)
+	}
+	var alts []string
+	for i := f; i <= t; i++ {
+		alts = append(alts, fmt.Sprintf("%0*d", width, i))
+	}
+	return alts, nil
+}
+
+// Returns target of the host, fields missed in the host are taken from defaults. Defaults aren't modified.
+func (h *Host) Target(defaults *lib.SRLTarget) *lib.SRLTarget {
+	t := *defaults
+	hostname := h.Hostname
+	t.Hostname = &hostname
+	if h.Username != "" {
+		t.Username = &h.Username
+	}
+	if h.Password != "" {
+		t.Password = &h.Password
+	}
+	if h.PortSSH != 0 {
+		t.PortSSH = &h.PortSSH
+	}
+	if h.PortJRpc != 0 {
+		t.PortJRpc = &h.PortJRpc
+	}
+	if h.PortgNOI != 0 {
+		t.PortgNOI = &h.PortgNOI
+	}
+	if h.PortgNMI != 0 {
+		t.PortgNMI = &h.PortgNMI
+	}
+	if h.Timeout != 0 {
+		t.Timeout = &h.Timeout
+	}
+	return &t
+}
@@ -0,0 +1,107 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadYAML(t *testing.T) {
+	inv := `
+defaults:
+  username: backup
+  password: secret
+  transports: [gnoi, jsonrpc]
+hosts:
+  - hostname: leaf1
+  - hostname: spine1
+    password: other
+    jrpc-port: 8443
+    timeout: 30s
+    transports: [ssh]
+`
+	hs, err := ReadYAML(strings.NewReader(inv))
+	if err != nil {
+		t.Fatalf("got an error from ReadYAML(): %v", err)
+	}
+	want := []*Host{
+		{Hostname: "leaf1", Username: "backup", Password: "secret", Transports: []string{"gnoi", "jsonrpc"}},
+		{Hostname: "spine1", Username: "backup", Password: "other", PortJRpc: 8443, Timeout: 30 * time.Second, Transports: []string{"ssh"}},
+	}
+	if diff := cmp.Diff(want, hs); diff != "" {
+		t.Errorf("ReadYAML() mismatch (-want +got):\n%s", diff)
+	}
+
+	hs, err = ReadYAML(strings.NewReader("- hostname: leaf1\n- hostname: leaf2\n"))
+	if err != nil || len(hs) != 2 {
+		t.Errorf("expected 2 hosts of the list; got: %v, %v", hs, err)
+	}
+	if _, err := ReadYAML(strings.NewReader("- hostname: leaf1\n- hostname: leaf1\n")); err == nil {
+		t.Errorf("expected an error for duplicated host")
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	inv := `hostname,username,password,ssh-port,transports
+# lab switches
+leaf1,admin,NokiaSrl1!,,gnoi;jsonrpc
+spine1,,,2222,ssh
+`
+	hs, err := ReadCSV(strings.NewReader(inv))
+	if err != nil {
+		t.Fatalf("got an error from ReadCSV(): %v", err)
+	}
+	want := []*Host{
+		{Hostname: "leaf1", Username: "admin", Password: "NokiaSrl1!", Transports: []string{"gnoi", "jsonrpc"}},
+		{Hostname: "spine1", PortSSH: 2222, Transports: []string{"ssh"}},
+	}
+	if diff := cmp.Diff(want, hs); diff != "" {
+		t.Errorf("ReadCSV() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := ReadCSV(strings.NewReader("hostname,port\nleaf1,22\n")); err == nil {
+		t.Errorf("expected an error for unknown column")
+	}
+	if _, err := ReadCSV(strings.NewReader("hostname,ssh-port\nleaf1,ssh\n")); err == nil {
+		t.Errorf("expected an error for incorrect port")
+	}
+}
+
+func TestExpand(t *testing.T) {
+	hs, err := Expand("leaf{1..3},spine{a,b},border{08..10}.lab, oob")
+	if err != nil {
+		t.Fatalf("got an error from Expand(): %v", err)
+	}
+	var got []string
+	for _, h := range hs {
+		got = append(got, h.Hostname)
+	}
+	want := []string{"leaf1", "leaf2", "leaf3", "spinea", "spineb", "border08.lab", "border09.lab", "border10.lab", "oob"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Expand() mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, p := range []string{"leaf{1..3", "leaf1}", "leaf{3..1}", "leaf{1,1}", "leaf{a..b}"} {
+		if _, err := Expand(p); err == nil {
+			t.Errorf("Expand(%s) expected to fail", p)
+		}
+	}
+}
+
+func TestTarget(t *testing.T) {
+	username, password, port := "admin", "NokiaSrl1!", 443
+	timeout := 10 * time.Second
+	d := new(lib.SRLTarget)
+	d.Username, d.Password, d.PortJRpc, d.Timeout = &username, &password, &port, &timeout
+
+	tg := (&Host{Hostname: "leaf1", Password: "other", PortJRpc: 8443}).Target(d)
+	if *tg.Hostname != "leaf1" || *tg.Username != "admin" || *tg.Password != "other" || *tg.PortJRpc != 8443 || *tg.Timeout != timeout {
+		t.Errorf("incorrect target: %s %s %s %d %s", *tg.Hostname, *tg.Username, *tg.Password, *tg.PortJRpc, *tg.Timeout)
+	}
+	if *d.Password != "NokiaSrl1!" || *d.PortJRpc != 443 {
+		t.Errorf("defaults are modified")
+	}
+}
@@ -0,0 +1,65 @@
+package jrpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors, callers are expected to match them with errors.Is().
+var (
+	ErrAuthFailed        = errors.New("authentication failed")
+	ErrIDMismatch        = errors.New("response id doesn't match request id")
+	ErrMalformedResponse = errors.New("malformed response")
+)
+
+// Error is returned by ExecCli for the failures reported by JSON-RPC server either on HTTP or on JSON-RPC level.
+type Error struct {
+	StatusCode int         // HTTP status code of the response.
+	Code       int         // RpcError.Code, 0 if HTTP level error.
+	Message    string      // RpcError.Message or HTTP status.
+	Data       interface{} // RpcError.Data, if provided by the server.
+	Err        error       // Underlying sentinel error, if any.
+}
+
+func (e *Error) Error() string {
+	var s string
+	if e.Code != 0 {
+		s = fmt.Sprintf("JSON-RPC error: code %d: %s", e.Code, e.Message)
+		if e.Data != nil {
+			s = fmt.Sprintf("%s: %v", s, e.Data)
+		}
+	} else {
+		s = fmt.Sprintf("http status: %d %s", e.StatusCode, e.Message)
+	}
+	if e.Err != nil {
+		s = fmt.Sprintf("%s: %s", e.Err, s)
+	}
+	return s
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Creates new Error out of HTTP response status.
+func newHTTPError(resp *http.Response) *Error {
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		e.Err = ErrAuthFailed
+	}
+	return e
+}
+
+// Creates new Error out of RpcError returned within JSON-RPC response.
+func newRpcError(statusCode int, rpcErr *RpcError) *Error {
+	return &Error{
+		StatusCode: statusCode,
+		Code:       rpcErr.Code,
+		Message:    rpcErr.Message,
+		Data:       rpcErr.Data,
+	}
+}
@@ -2,4 +2,9 @@ module github.com/azyablov/fat/lib/jrpc
 
 go 1.18
 
-require github.com/azyablov/fat/lib v0.0.0-20230317114747-14ecb7a2410e
+require (
+	github.com/azyablov/fat/lib v0.0.0-20230317114747-14ecb7a2410e
+	github.com/google/go-cmp v0.5.9
+)
+
+replace github.com/azyablov/fat/lib => ../
@@ -2,11 +2,11 @@ package jrpc
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"log"
 	"math/rand"
 	"net/http"
 	"time"
@@ -55,44 +55,83 @@ type RpcError struct {
 
 func ExecCli(t *lib.SRLTarget, cmd *string, f OutputFormat) (*JSONRpcResponse, error) {
 
-	if len(*cmd) == 0 || cmd == nil {
+	if cmd == nil || len(*cmd) == 0 {
 		return nil, fmt.Errorf("command can't be null string or nil")
 	}
+	return ExecCliCmds(t, []string{*cmd}, f)
+}
+
+// Function executes the list of CLI commands within single JSON-RPC request, so they share the same CLI session.
+// Result contains one element per command.
+func ExecCliCmds(t *lib.SRLTarget, cmds []string, f OutputFormat) (*JSONRpcResponse, error) {
+	return ExecCliCmdsContext(context.Background(), t, cmds, f)
+}
+
+// Same as ExecCliCmds(), but the request is aborted and not retried, once ctx is done.
+func ExecCliCmdsContext(ctx context.Context, t *lib.SRLTarget, cmds []string, f OutputFormat) (*JSONRpcResponse, error) {
+
+	if len(cmds) == 0 {
+		return nil, fmt.Errorf("list of commands can't be empty")
+	}
 	var outFormat OutputFormat
 
 	switch f {
-	case OutFormJSON:
-	case OutFormText:
-	case OutFormTable:
+	case OutFormJSON, OutFormText, OutFormTable:
+		outFormat = f
 	case "":
 		outFormat = OutFormJSON
 	default:
 		return nil, fmt.Errorf("provided output format isn't supported")
 	}
 
+	var iCmds []interface{}
+	idempotent := true
+	for _, c := range cmds {
+		if len(c) == 0 {
+			return nil, fmt.Errorf("command can't be null string")
+		}
+		iCmds = append(iCmds, c)
+		idempotent = idempotent && isIdempotentCli(c)
+	}
+	params := Params{
+		Commands:  iCmds,
+		OutFormat: outFormat,
+	}
+
+	// Executing request, retrying it, if policy is attached to the target.
+	var rpcResp *JSONRpcResponse
+	retry := retryable(idempotent)
+	err := t.Retry.DoContext(ctx, func(err error) bool { return ctx.Err() == nil && retry(err) }, func() error {
+		var err error
+		rpcResp, err = call(ctx, t, MethodCli, params)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rpcResp, nil
+}
+
+// Function sends JSON-RPC request toward the target and validates the response.
+func call(ctx context.Context, t *lib.SRLTarget, m Method, params Params) (*JSONRpcResponse, error) {
 	// Setting up request,
 	rand.Seed(time.Now().UnixNano())
 	id := rand.Int()
-	var cmds []interface{}
-	cmds = append(cmds, *cmd)
 	rpcReq := JSONRpcRequest{
 		JSONRpcVersion: "2.0",
 		ID:             id,
-		Method:         MethodCli,
-		Params: Params{
-			Commands:  cmds,
-			OutFormat: outFormat,
-		},
+		Method:         m,
+		Params:         params,
 	}
 	// marshalling to []byte
 	bRpcReq, err := json.Marshal(rpcReq)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("request marshalling error: %s", err)
 	}
 	// ... creating an HTTP POST request
-	reqHTTP, err := http.NewRequest("POST", fmt.Sprintf("https://%s:%v/jsonrpc", *t.Hostname, *t.PortJRpc), bytes.NewBuffer(bRpcReq))
+	reqHTTP, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("https://%s:%v/jsonrpc", *t.Hostname, *t.PortJRpc), bytes.NewBuffer(bRpcReq))
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("can't create http request: %s", err)
 	}
 	// setting content type and authentication header
 	reqHTTP.Header.Set("Content-Type", "application/json")
@@ -107,26 +146,31 @@ func ExecCli(t *lib.SRLTarget, cmd *string, f OutputFormat) (*JSONRpcResponse, e
 
 	resp, err := client.Do(reqHTTP)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("http request failed: %w", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http status: %s", resp.Status)
+		return nil, newHTTPError(resp)
 	}
 
 	var rpcResp JSONRpcResponse
 	err = json.NewDecoder(resp.Body).Decode(&rpcResp)
 	if err != nil {
-		return nil, fmt.Errorf("decoding error: %s", err)
+		return nil, fmt.Errorf("%w: decoding error: %s", ErrMalformedResponse, err)
 	}
 	// Checking for RPC error presence
 	if rpcResp.Error != nil {
-		return nil, fmt.Errorf("got an JSON-RPC error: %v", rpcResp.Error)
+		return nil, newRpcError(resp.StatusCode, rpcResp.Error)
 	}
 
 	// Checking for id match
 	if rpcResp.ID != id {
-		return nil, fmt.Errorf("got an JSON-RPC error: %v", rpcResp.Error)
+		return nil, fmt.Errorf("%w: expected %d, got %d", ErrIDMismatch, id, rpcResp.ID)
+	}
+
+	// Checking for result presence
+	if rpcResp.Result == nil {
+		return nil, fmt.Errorf("%w: neither result nor error found", ErrMalformedResponse)
 	}
 
 	return &rpcResp, nil
@@ -0,0 +1,179 @@
+package jrpc_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/jrpc"
+)
+
+// Starts JSON-RPC server mock using provided handler and returns target pointing to it.
+func newTestTarget(t *testing.T, h http.HandlerFunc) *lib.SRLTarget {
+	t.Helper()
+	srv := httptest.NewTLSServer(h)
+	t.Cleanup(srv.Close)
+
+	host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("can't parse test server address: %v", err)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("can't parse test server port: %v", err)
+	}
+	username, password := "admin", "admin"
+	timeout := 5 * time.Second
+	tg := new(lib.SRLTarget)
+	tg.Hostname = &host
+	tg.PortJRpc = &p
+	tg.Username = &username
+	tg.Password = &password
+	tg.Timeout = &timeout
+	return tg
+}
+
+// Replies with the provided result and error, id is taken from request unless overridden.
+func rpcReply(t *testing.T, result interface{}, rpcErr *jrpc.RpcError, id *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req jrpc.JSONRpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("can't decode request: %v", err)
+		}
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if id != nil {
+			resp["id"] = *id
+		}
+		if result != nil {
+			resp["result"] = result
+		}
+		if rpcErr != nil {
+			resp["error"] = rpcErr
+		}
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func TestExecCliErrors(t *testing.T) {
+	wrongID := -1
+	cmd := "show version"
+
+	testData := []struct {
+		testName string
+		handler  http.HandlerFunc
+		expErr   error
+		expCode  int
+		expHTTP  int
+	}{
+		{
+			testName: "Checking err: authentication failure",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			expErr:  jrpc.ErrAuthFailed,
+			expHTTP: http.StatusUnauthorized,
+		},
+		{
+			testName: "Checking err: http 5xx",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			expHTTP: http.StatusServiceUnavailable,
+		},
+		{
+			testName: "Checking err: malformed response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("{not a json"))
+			},
+			expErr: jrpc.ErrMalformedResponse,
+		},
+		{
+			testName: "Checking err: id mismatch",
+			handler:  rpcReply(t, []interface{}{}, nil, &wrongID),
+			expErr:   jrpc.ErrIDMismatch,
+		},
+		{
+			testName: "Checking err: JSON-RPC error",
+			handler:  rpcReply(t, nil, &jrpc.RpcError{Code: -1, Message: "Parse error on line 1"}, nil),
+			expCode:  -1,
+			expHTTP:  http.StatusOK,
+		},
+	}
+
+	for _, d := range testData {
+		t.Run(d.testName, func(t *testing.T) {
+			tg := newTestTarget(t, d.handler)
+			_, err := jrpc.ExecCli(tg, &cmd, jrpc.OutFormJSON)
+			t.Log(err)
+			if err == nil {
+				t.Fatalf("expected an error, got nil")
+			}
+			if d.expErr != nil && !errors.Is(err, d.expErr) {
+				t.Errorf("expected error: %v; got: %v", d.expErr, err)
+			}
+			if d.expHTTP != 0 || d.expCode != 0 {
+				var rpcErr *jrpc.Error
+				if !errors.As(err, &rpcErr) {
+					t.Fatalf("expected *jrpc.Error; got: %T", err)
+				}
+				if rpcErr.StatusCode != d.expHTTP || rpcErr.Code != d.expCode {
+					t.Errorf("expected http status %d and code %d; got: %d and %d", d.expHTTP, d.expCode, rpcErr.StatusCode, rpcErr.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestExecCli(t *testing.T) {
+	cmd := "show version"
+	tg := newTestTarget(t, rpcReply(t, []interface{}{map[string]string{"text": "ok"}}, nil, nil))
+	resp, err := jrpc.ExecCli(tg, &cmd, jrpc.OutFormText)
+	if err != nil {
+		t.Fatalf("got an error from ExecCli(): %v", err)
+	}
+	if string(lib.JSONRawToByte(resp.Result)) != `[{"text":"ok"}]` {
+		t.Errorf("incorrect result: %s", lib.JSONRawToByte(resp.Result))
+	}
+}
+
+func TestExecCliRetry(t *testing.T) {
+	testData := []struct {
+		testName string
+		cmd      string
+		expCalls int
+		expErr   bool
+	}{
+		{testName: "idempotent command is retried on 5xx", cmd: "show version", expCalls: 3, expErr: false},
+		{testName: "non idempotent command isn't retried on 5xx", cmd: "file rm /tmp/myconfig.cfg", expCalls: 1, expErr: true},
+	}
+
+	for _, d := range testData {
+		t.Run(d.testName, func(t *testing.T) {
+			var calls int
+			ok := rpcReply(t, []interface{}{}, nil, nil)
+			tg := newTestTarget(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				ok(w, r)
+			})
+			tg.Retry = lib.NewRetryPolicy(3, time.Millisecond)
+
+			_, err := jrpc.ExecCli(tg, &d.cmd, jrpc.OutFormText)
+			if (err != nil) != d.expErr {
+				t.Errorf("unexpected error state: %v", err)
+			}
+			if calls != d.expCalls {
+				t.Errorf("expected %d calls; got: %d", d.expCalls, calls)
+			}
+		})
+	}
+}
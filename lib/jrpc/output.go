@@ -0,0 +1,152 @@
+package jrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/azyablov/fat/lib"
+)
+
+// Table is the parsed table output of the CLI command.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Executes CLI command in text format and returns its output as plain string.
+func ExecCliText(t *lib.SRLTarget, cmd string) (string, error) {
+	return ExecCliTextContext(context.Background(), t, cmd)
+}
+
+// Same as ExecCliText(), but the request is aborted, once ctx is done.
+func ExecCliTextContext(ctx context.Context, t *lib.SRLTarget, cmd string) (string, error) {
+	out, err := execTexts(ctx, t, []string{cmd}, OutFormText)
+	if err != nil {
+		return "", err
+	}
+	return out[0], nil
+}
+
+// Executes the list of CLI commands in text format within single request and returns output per command.
+func ExecCliTexts(t *lib.SRLTarget, cmds []string) ([]string, error) {
+	return execTexts(context.Background(), t, cmds, OutFormText)
+}
+
+// Executes CLI command in table format and returns parsed tables, a show command may render several ones.
+func ExecCliTable(t *lib.SRLTarget, cmd string) ([]*Table, error) {
+	out, err := execTexts(context.Background(), t, []string{cmd}, OutFormTable)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTables(out[0]), nil
+}
+
+// Function returns plain text output per command from the result envelope `[{"text": "..."}, {}, ...]`.
+// Commands w/o output are represented by empty string.
+func Texts(resp *JSONRpcResponse) ([]string, error) {
+	if resp == nil || resp.Result == nil {
+		return nil, fmt.Errorf("%w: no result", ErrMalformedResponse)
+	}
+	var out []map[string]string
+	err := json.Unmarshal(lib.JSONRawToByte(resp.Result), &out)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMalformedResponse, err)
+	}
+	texts := make([]string, 0, len(out))
+	for _, o := range out {
+		texts = append(texts, o["text"])
+	}
+	return texts, nil
+}
+
+// Function parses table output, as rendered by SR Linux CLI, into tables.
+// Rows are lines delimited by `|` or `│`, while the line with `=` or `═` separates header from the rows.
+// If no such separator found, the first row is taken as header.
+func ParseTables(s string) []*Table {
+	var tables []*Table
+	var cur *Table
+	var header bool
+
+	closeTable := func() {
+		if cur == nil {
+			return
+		}
+		if !header && len(cur.Rows) > 0 {
+			cur.Header, cur.Rows = cur.Rows[0], cur.Rows[1:]
+		}
+		tables = append(tables, cur)
+		cur = nil
+	}
+
+	for _, l := range strings.Split(s, "\n") {
+		l = strings.TrimSpace(l)
+		switch {
+		case strings.HasPrefix(l, "|") || strings.HasPrefix(l, "│"):
+			if cur == nil {
+				cur, header = new(Table), false
+			}
+			cur.Rows = append(cur.Rows, splitRow(l))
+		case cur != nil && !header && isBorder(l) && strings.ContainsAny(l, "=═"):
+			// Header separator, all rows collected so far are the header.
+			cur.Header = mergeHeader(cur.Rows)
+			cur.Rows = nil
+			header = true
+		case cur != nil && len(l) != 0 && isBorder(l):
+			// Border line, table continues.
+		default:
+			// Anything else, e.g. title or summary, closes the table.
+			closeTable()
+		}
+	}
+	closeTable()
+	return tables
+}
+
+// Function returns true, if line consists of box drawing characters only.
+func isBorder(l string) bool {
+	return strings.Trim(l, "+-=|:─━│┃┌┐└┘├┤┬┴┼═║╒╓╔╕╖╗╘╙╚╛╜╝╞╟╠╡╢╣╤╥╦╧╨╩╪╫╬ ") == ""
+}
+
+// Function splits table row into trimmed cells.
+func splitRow(l string) []string {
+	l = strings.ReplaceAll(l, "│", "|")
+	l = strings.TrimPrefix(l, "|")
+	l = strings.TrimSuffix(l, "|")
+	cells := strings.Split(l, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// Function merges multi-line header into the single one, joining cells column-wise.
+func mergeHeader(rows [][]string) []string {
+	if len(rows) == 0 {
+		return nil
+	}
+	h := make([]string, len(rows[0]))
+	for _, r := range rows {
+		for i := 0; i < len(r) && i < len(h); i++ {
+			h[i] = strings.TrimSpace(strings.Join([]string{h[i], r[i]}, " "))
+		}
+	}
+	return h
+}
+
+// Function executes commands with provided format and returns text output per command.
+func execTexts(ctx context.Context, t *lib.SRLTarget, cmds []string, f OutputFormat) ([]string, error) {
+	resp, err := ExecCliCmdsContext(ctx, t, cmds, f)
+	if err != nil {
+		return nil, err
+	}
+	out, err := Texts(resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) != len(cmds) {
+		return nil, fmt.Errorf("%w: expected output of %d commands, got %d", ErrMalformedResponse, len(cmds), len(out))
+	}
+	return out, nil
+}
@@ -0,0 +1,56 @@
+package jrpc_test
+
+import (
+	"testing"
+
+	"github.com/azyablov/fat/lib/jrpc"
+	"github.com/google/go-cmp/cmp"
+)
+
+const sampleTable = `+---------------------+------------+-------------+
+|        Name         |    Type    | Admin state |
+|                     |            |             |
++=====================+============+=============+
+| default             | default    | enable      |
+| mgmt                | ip-vrf     | enable      |
++---------------------+------------+-------------+
+Summary: 2 network-instances
+╭──────────────┬──────────╮
+│ Port         │ Speed    │
+├──────────────┼──────────┤
+│ ethernet-1/1 │ 25G      │
+╰──────────────┴──────────╯
+`
+
+func TestParseTables(t *testing.T) {
+	exp := []*jrpc.Table{
+		{
+			Header: []string{"Name", "Type", "Admin state"},
+			Rows:   [][]string{{"default", "default", "enable"}, {"mgmt", "ip-vrf", "enable"}},
+		},
+		{
+			Header: []string{"Port", "Speed"},
+			Rows:   [][]string{{"ethernet-1/1", "25G"}},
+		},
+	}
+	if diff := cmp.Diff(exp, jrpc.ParseTables(sampleTable)); diff != "" {
+		t.Errorf("ParseTables() mismatch (-exp +got):\n%s", diff)
+	}
+	if tables := jrpc.ParseTables("no tables here\n"); len(tables) != 0 {
+		t.Errorf("expected no tables; got: %v", tables)
+	}
+}
+
+func TestExecCliTexts(t *testing.T) {
+	tg := newTestTarget(t, rpcReply(t, []interface{}{map[string]string{"text": "out"}, map[string]string{}}, nil, nil))
+	out, err := jrpc.ExecCliTexts(tg, []string{"show version", "info > /tmp/cfg"})
+	if err != nil {
+		t.Fatalf("got an error from ExecCliTexts(): %v", err)
+	}
+	if diff := cmp.Diff([]string{"out", ""}, out); diff != "" {
+		t.Errorf("ExecCliTexts() mismatch (-exp +got):\n%s", diff)
+	}
+	if _, err := jrpc.ExecCliText(tg, "show version"); err == nil {
+		t.Errorf("expected an error for mismatching number of outputs")
+	}
+}
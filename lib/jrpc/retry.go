@@ -0,0 +1,35 @@
+package jrpc
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// Function returns true, if CLI command doesn't change the state of the target and can be safely re-executed.
+func isIdempotentCli(cmd string) bool {
+	c := strings.TrimSpace(cmd)
+	for _, p := range []string{"show ", "info"} {
+		if strings.HasPrefix(c, p) {
+			return true
+		}
+	}
+	return c == "show"
+}
+
+// Function returns retry predicate for the JSON-RPC call.
+// Refused connections are always retried, since request didn't reach the server,
+// while HTTP 5xx are retried only for idempotent calls.
+func retryable(idempotent bool) func(error) bool {
+	return func(err error) bool {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return true
+		}
+		var e *Error
+		if idempotent && errors.As(err, &e) && e.Code == 0 && e.StatusCode >= http.StatusInternalServerError {
+			return true
+		}
+		return false
+	}
+}
@@ -0,0 +1,174 @@
+// Package show provides typed decoders for the JSON output of frequently used SR Linux show commands executed via JSON-RPC.
+package show
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/jrpc"
+)
+
+const (
+	cmdVersion        = "show version"
+	cmdInterfaceBrief = "show interface brief"
+	cmdNetInstSummary = "show network-instance summary"
+	cmdBGPNeighbor    = "show network-instance %s protocols bgp neighbor"
+	cmdLLDPNeighbor   = "show system lldp neighbor"
+	keyVersion        = "basic system info"
+	keyInterfaceBrief = "interface"
+	keyNetInstSummary = "Network Instances"
+	keyBGPNeighbor    = "BGP neighbor summary"
+	keyLLDPNeighbor   = "Neighbor"
+)
+
+// Output of `show version`.
+type Version struct {
+	Hostname        string `json:"Hostname"`
+	ChassisType     string `json:"Chassis Type"`
+	PartNumber      string `json:"Part Number"`
+	SerialNumber    string `json:"Serial Number"`
+	SystemMAC       string `json:"System HW MAC Address"`
+	SoftwareVersion string `json:"Software Version"`
+	BuildNumber     string `json:"Build Number"`
+	Architecture    string `json:"Architecture"`
+	LastBooted      string `json:"Last Booted"`
+	TotalMemory     string `json:"Total Memory"`
+	FreeMemory      string `json:"Free Memory"`
+}
+
+// Row of `show interface brief`.
+type Interface struct {
+	Port        string `json:"Port"`
+	AdminState  string `json:"Admin State"`
+	OperState   string `json:"Oper State"`
+	Speed       string `json:"Speed"`
+	Type        string `json:"Type"`
+	Description string `json:"Description"`
+}
+
+// Row of `show network-instance summary`.
+type NetworkInstance struct {
+	Name        string `json:"Name"`
+	Type        string `json:"Type"`
+	AdminState  string `json:"Admin state"`
+	OperState   string `json:"Oper state"`
+	RouterID    string `json:"Router id"`
+	Description string `json:"Description"`
+}
+
+// Row of `show network-instance <name> protocols bgp neighbor`.
+type BGPNeighbor struct {
+	NetworkInstance string `json:"Net-Inst"`
+	Peer            string `json:"Peer"`
+	Group           string `json:"Group"`
+	Flags           string `json:"Flags"`
+	PeerAS          string `json:"Peer-AS"`
+	State           string `json:"State"`
+	Uptime          string `json:"Uptime"`
+	AFISAFI         string `json:"AFI/SAFI"`
+	Routes          string `json:"[Rx/Active/Tx]"`
+}
+
+// Row of `show system lldp neighbor`.
+type LLDPNeighbor struct {
+	Name         string `json:"Name"`
+	Neighbor     string `json:"Neighbor"`
+	SystemName   string `json:"Neighbor System Name"`
+	ChassisID    string `json:"Neighbor Chassis ID"`
+	FirstMessage string `json:"Neighbor First Message"`
+	LastUpdate   string `json:"Neighbor Last Update"`
+	Port         string `json:"Neighbor Port"`
+}
+
+// Executes `show version` and returns decoded output.
+func GetVersion(t *lib.SRLTarget) (*Version, error) {
+	var out map[string]Version
+	if err := execDecode(t, cmdVersion, &out); err != nil {
+		return nil, err
+	}
+	v, ok := out[keyVersion]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q not found in %s output", jrpc.ErrMalformedResponse, keyVersion, cmdVersion)
+	}
+	return &v, nil
+}
+
+// Executes `show interface brief` and returns decoded table rows.
+func GetInterfaces(t *lib.SRLTarget) ([]Interface, error) {
+	var rows []Interface
+	if err := execDecodeRows(t, cmdInterfaceBrief, keyInterfaceBrief, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Executes `show network-instance summary` and returns decoded table rows.
+func GetNetworkInstances(t *lib.SRLTarget) ([]NetworkInstance, error) {
+	var rows []NetworkInstance
+	if err := execDecodeRows(t, cmdNetInstSummary, keyNetInstSummary, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Executes `show network-instance <ni> protocols bgp neighbor` and returns decoded table rows.
+func GetBGPNeighbors(t *lib.SRLTarget, ni string) ([]BGPNeighbor, error) {
+	if len(ni) == 0 {
+		return nil, fmt.Errorf("network-instance can't be null string")
+	}
+	var rows []BGPNeighbor
+	if err := execDecodeRows(t, fmt.Sprintf(cmdBGPNeighbor, ni), keyBGPNeighbor, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Executes `show system lldp neighbor` and returns decoded table rows.
+func GetLLDPNeighbors(t *lib.SRLTarget) ([]LLDPNeighbor, error) {
+	var rows []LLDPNeighbor
+	if err := execDecodeRows(t, cmdLLDPNeighbor, keyLLDPNeighbor, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Function executes command in JSON format and decodes its output into v.
+func execDecode(t *lib.SRLTarget, cmd string, v interface{}) error {
+	resp, err := jrpc.ExecCli(t, &cmd, jrpc.OutFormJSON)
+	if err != nil {
+		return fmt.Errorf("can't exec %q: %w", cmd, err)
+	}
+	// Result holds one element per command.
+	var out []json.RawMessage
+	if err := json.Unmarshal(lib.JSONRawToByte(resp.Result), &out); err != nil {
+		return fmt.Errorf("%w: %s", jrpc.ErrMalformedResponse, err)
+	}
+	if len(out) != 1 {
+		return fmt.Errorf("%w: expected output of single command, got %d", jrpc.ErrMalformedResponse, len(out))
+	}
+	if err := json.Unmarshal(out[0], v); err != nil {
+		return fmt.Errorf("%w: can't decode %q output: %s", jrpc.ErrMalformedResponse, cmd, err)
+	}
+	return nil
+}
+
+// Function executes command in JSON format and decodes rows of the table with key title into rows.
+// Table outputs are objects keyed by table title; commands without any match return empty object.
+func execDecodeRows(t *lib.SRLTarget, cmd string, key string, rows interface{}) error {
+	var out map[string]json.RawMessage
+	if err := execDecode(t, cmd, &out); err != nil {
+		return err
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	table, ok := out[key]
+	if !ok {
+		return fmt.Errorf("%w: %q not found in %s output", jrpc.ErrMalformedResponse, key, cmd)
+	}
+	if err := json.Unmarshal(table, rows); err != nil {
+		return fmt.Errorf("%w: can't decode %q rows: %s", jrpc.ErrMalformedResponse, cmd, err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package show_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/jrpc"
+	"github.com/azyablov/fat/lib/jrpc/show"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Canned JSON outputs of the show commands.
+var outputs = map[string]string{
+	"show version": `{"basic system info": {"Hostname": "srl1", "Chassis Type": "7220 IXR-D3L", "Software Version": "v22.6.4"}}`,
+	"show interface brief": `{"breakout": [{"Port": "ethernet-1/3"}], "interface": [
+		{"Port": "ethernet-1/1", "Admin State": "enable", "Oper State": "up", "Speed": "25G", "Type": "", "Description": "to spine"}
+	]}`,
+	"show network-instance summary": `{"title": "Network Instances", "Network Instances": [
+		{"Name": "mgmt", "Type": "ip-vrf", "Admin state": "enable", "Oper state": "up", "Router id": "", "Description": "Management"}
+	]}`,
+	"show network-instance default protocols bgp neighbor": `{}`,
+	"show network-instance mgmt protocols bgp neighbor":    `{"BGP neighbors": []}`,
+	"show system lldp neighbor": `{"Neighbor": [
+		{"Name": "ethernet-1/1", "Neighbor": "1A:8C:02:FF:00:00", "Neighbor System Name": "spine1", "Neighbor Port": "ethernet-1/3"}
+	]}`,
+}
+
+func newTestTarget(t *testing.T) *lib.SRLTarget {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jrpc.JSONRpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("can't decode request: %v", err)
+		}
+		out, ok := outputs[req.Params.Commands[0].(string)]
+		if !ok {
+			t.Errorf("unexpected command: %v", req.Params.Commands[0])
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": []json.RawMessage{json.RawMessage(out)}})
+	}))
+	t.Cleanup(srv.Close)
+
+	host, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	username, password := "admin", "admin"
+	timeout := 5 * time.Second
+	tg := new(lib.SRLTarget)
+	tg.Hostname = &host
+	tg.PortJRpc = &p
+	tg.Username = &username
+	tg.Password = &password
+	tg.Timeout = &timeout
+	return tg
+}
+
+func TestShow(t *testing.T) {
+	tg := newTestTarget(t)
+
+	v, err := show.GetVersion(tg)
+	if err != nil {
+		t.Fatalf("got an error from GetVersion(): %v", err)
+	}
+	if diff := cmp.Diff(&show.Version{Hostname: "srl1", ChassisType: "7220 IXR-D3L", SoftwareVersion: "v22.6.4"}, v); diff != "" {
+		t.Errorf("GetVersion() mismatch (-exp +got):\n%s", diff)
+	}
+
+	ifs, err := show.GetInterfaces(tg)
+	if err != nil {
+		t.Fatalf("got an error from GetInterfaces(): %v", err)
+	}
+	if diff := cmp.Diff([]show.Interface{{Port: "ethernet-1/1", AdminState: "enable", OperState: "up", Speed: "25G", Description: "to spine"}}, ifs); diff != "" {
+		t.Errorf("GetInterfaces() mismatch (-exp +got):\n%s", diff)
+	}
+
+	nis, err := show.GetNetworkInstances(tg)
+	if err != nil {
+		t.Fatalf("got an error from GetNetworkInstances(): %v", err)
+	}
+	if diff := cmp.Diff([]show.NetworkInstance{{Name: "mgmt", Type: "ip-vrf", AdminState: "enable", OperState: "up", Description: "Management"}}, nis); diff != "" {
+		t.Errorf("GetNetworkInstances() mismatch (-exp +got):\n%s", diff)
+	}
+
+	nbrs, err := show.GetBGPNeighbors(tg, "default")
+	if err != nil {
+		t.Fatalf("got an error from GetBGPNeighbors(): %v", err)
+	}
+	if len(nbrs) != 0 {
+		t.Errorf("expected no BGP neighbors; got: %v", nbrs)
+	}
+	if _, err := show.GetBGPNeighbors(tg, ""); err == nil {
+		t.Errorf("expected an error for empty network-instance")
+	}
+	if _, err := show.GetBGPNeighbors(tg, "mgmt"); !errors.Is(err, jrpc.ErrMalformedResponse) {
+		t.Errorf("expected ErrMalformedResponse for unknown table; got: %v", err)
+	}
+
+	lldp, err := show.GetLLDPNeighbors(tg)
+	if err != nil {
+		t.Fatalf("got an error from GetLLDPNeighbors(): %v", err)
+	}
+	if diff := cmp.Diff([]show.LLDPNeighbor{{Name: "ethernet-1/1", Neighbor: "1A:8C:02:FF:00:00", SystemName: "spine1", Port: "ethernet-1/3"}}, lldp); diff != "" {
+		t.Errorf("GetLLDPNeighbors() mismatch (-exp +got):\n%s", diff)
+	}
+}
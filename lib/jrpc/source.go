@@ -0,0 +1,66 @@
+package jrpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/azyablov/fat/lib"
+)
+
+// Name of the transport config source is registered under.
+const SourceName = "jsonrpc"
+
+func init() {
+	lib.RegisterConfigSource(SourceName, NewConfigSource)
+}
+
+// ConfigSource extracts info config via CLI commands executed over JSON-RPC.
+type ConfigSource struct {
+	ctx context.Context
+	t   *lib.SRLTarget
+}
+
+// Creates config source, JSON-RPC is stateless, so nothing is opened till the first call.
+func NewConfigSource(ctx context.Context, t *lib.SRLTarget) (lib.ConfigSource, error) {
+	return &ConfigSource{ctx: ctx, t: t}, nil
+}
+
+// Returns hostname, chassis type and software version parsed from `show version`.
+func (s *ConfigSource) ShowVersion() (*lib.SystemVersion, error) {
+	out, err := ExecCliTextContext(s.ctx, s.t, "show version")
+	if err != nil {
+		return nil, sourceError(err)
+	}
+	return lib.ParseShowVersion(out)
+}
+
+// Returns running config as `info` outputs it.
+func (s *ConfigSource) Info() (string, error) {
+	out, err := ExecCliTextContext(s.ctx, s.t, "info | as text")
+	return out, sourceError(err)
+}
+
+// Returns lib.ConfigFormatInfo.
+func (s *ConfigSource) Format() string {
+	return lib.ConfigFormatInfo
+}
+
+// Executes CLI command and returns its text output.
+func (s *ConfigSource) RunCLI(cmd string) (string, error) {
+	out, err := ExecCliTextContext(s.ctx, s.t, cmd)
+	return out, sourceError(err)
+}
+
+// Nothing to clean up.
+func (s *ConfigSource) Cleanup() error {
+	return nil
+}
+
+// Function marks authentication failure as lib.UnavailableError, so config extraction could fall back to another transport.
+// Connection failures are recognized by lib.IsUnavailable() as network errors.
+func sourceError(err error) error {
+	if errors.Is(err, ErrAuthFailed) {
+		return &lib.UnavailableError{Err: err}
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package jrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/jrpc"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConfigSource(t *testing.T) {
+	outputs := map[string]string{
+		"show version":   "Hostname             : leaf1\nChassis Type         : 7220 IXR-D2\nSoftware Version     : v23.3.1\n",
+		"info | as text": "    system {\n    }\n",
+	}
+	tg := newTestTarget(t, func(w http.ResponseWriter, r *http.Request) {
+		var req jrpc.JSONRpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("can't decode request: %v", err)
+		}
+		cmd, _ := req.Params.Commands[0].(string)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  []map[string]string{{"text": outputs[cmd]}},
+		})
+	})
+
+	e, err := lib.ExtractConfig(context.Background(), tg, jrpc.SourceName)
+	if err != nil {
+		t.Fatalf("got an error from ExtractConfig(): %v", err)
+	}
+	want := &lib.Extraction{
+		Transport: jrpc.SourceName,
+		Version:   &lib.SystemVersion{Hostname: "leaf1", ChassisType: "7220 IXR-D2", SoftwareVersion: "v23.3.1"},
+		Config:    outputs["info | as text"],
+		Format:    lib.ConfigFormatInfo,
+	}
+	if diff := cmp.Diff(want, e); diff != "" {
+		t.Errorf("ExtractConfig() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigSourceAborted(t *testing.T) {
+	hung := make(chan struct{})
+	tg := newTestTarget(t, func(w http.ResponseWriter, r *http.Request) {
+		// The target hangs till the test is over.
+		<-hung
+	})
+	t.Cleanup(func() { close(hung) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := lib.ExtractConfig(ctx, tg, jrpc.SourceName)
+	if err == nil || ctx.Err() == nil {
+		t.Errorf("expected extraction to be aborted by context; got: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package jrpc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+)
+
+// CLI commands used to drive candidate datastore.
+const (
+	cmdEnterPrivate  = "enter candidate private"
+	cmdEnterNamed    = "enter candidate name %s"
+	cmdSet           = "set / %s"
+	cmdDelete        = "delete / %s"
+	cmdDiff          = "diff"
+	cmdValidate      = "commit validate"
+	cmdCommitNow     = "commit now"
+	cmdCommitConfirm = "commit confirmed timeout %d"
+	cmdDiscardNow    = "discard now"
+	cmdConfirmAccept = "tools system configuration confirmed-accept"
+	cmdConfirmReject = "tools system configuration confirmed-reject"
+)
+
+const (
+	privateCandidate  = ""
+	maxConfirmTimeout = 24 * time.Hour
+)
+
+// Tx is a candidate datastore transaction executed over JSON-RPC.
+//
+// Private candidate lives only within single JSON-RPC request, so set/delete operations are re-applied
+// on every Validate(), Diff() and Commit() call. Named candidate is shared and survives between requests,
+// so operations are applied once, by the first call, and then are kept by the target until Commit() or Discard().
+type Tx struct {
+	t       *lib.SRLTarget
+	name    string   // Candidate name, empty for private candidate.
+	ops     []string // set/delete commands to apply within candidate.
+	applied bool     // ops are already applied to the named candidate.
+}
+
+// Creates new transaction using private candidate.
+func NewTx(t *lib.SRLTarget) *Tx {
+	return &Tx{t: t, name: privateCandidate}
+}
+
+// Creates new transaction using named candidate.
+func NewNamedTx(t *lib.SRLTarget, name string) (*Tx, error) {
+	if len(strings.TrimSpace(name)) == 0 {
+		return nil, fmt.Errorf("candidate name can't be null string")
+	}
+	return &Tx{t: t, name: name}, nil
+}
+
+// Adds set operation to the transaction, path is the CLI path with value relative to the root,
+// e.g. `interface ethernet-1/1 admin-state enable`.
+func (tx *Tx) Set(path string) *Tx {
+	tx.ops = append(tx.ops, fmt.Sprintf(cmdSet, strings.TrimSpace(path)))
+	return tx
+}
+
+// Adds delete operation to the transaction, path is the CLI path relative to the root.
+func (tx *Tx) Delete(path string) *Tx {
+	tx.ops = append(tx.ops, fmt.Sprintf(cmdDelete, strings.TrimSpace(path)))
+	return tx
+}
+
+// Validates candidate with the transaction operations applied.
+func (tx *Tx) Validate() error {
+	_, err := tx.exec(cmdValidate)
+	return err
+}
+
+// Returns the diff between candidate with transaction operations applied and running datastore.
+func (tx *Tx) Diff() (string, error) {
+	resp, err := tx.exec(cmdDiff)
+	if err != nil {
+		return "", err
+	}
+	out, err := Texts(resp)
+	if err != nil {
+		return "", err
+	}
+	if len(out) == 0 {
+		return "", fmt.Errorf("%w: empty result", ErrMalformedResponse)
+	}
+	return out[len(out)-1], nil
+}
+
+// Commits the transaction. If confirmTimeout isn't zero, commit has to be confirmed with
+// ConfirmCommit() before timeout expires, otherwise target rolls it back automatically.
+func (tx *Tx) Commit(confirmTimeout time.Duration) error {
+	cmd := cmdCommitNow
+	if confirmTimeout != 0 {
+		if confirmTimeout < time.Second || confirmTimeout > maxConfirmTimeout {
+			return fmt.Errorf("confirm timeout should be within [%v, %v]", time.Second, maxConfirmTimeout)
+		}
+		cmd = fmt.Sprintf(cmdCommitConfirm, int(confirmTimeout.Seconds()))
+	}
+	_, err := tx.exec(cmd)
+	if err != nil {
+		return err
+	}
+	tx.reset()
+	return nil
+}
+
+// Discards the transaction; named candidate is discarded on the target as well.
+func (tx *Tx) Discard() error {
+	defer tx.reset()
+	if tx.name == privateCandidate {
+		// Nothing is kept by the target.
+		return nil
+	}
+	_, err := ExecCliCmds(tx.t, []string{fmt.Sprintf(cmdEnterNamed, tx.name), cmdDiscardNow}, OutFormText)
+	return err
+}
+
+// Accepts commit previously done with confirm timeout.
+func ConfirmCommit(t *lib.SRLTarget) error {
+	_, err := ExecCliCmds(t, []string{cmdConfirmAccept}, OutFormText)
+	return err
+}
+
+// Rejects commit previously done with confirm timeout, so target rolls it back immediately.
+func RejectCommit(t *lib.SRLTarget) error {
+	_, err := ExecCliCmds(t, []string{cmdConfirmReject}, OutFormText)
+	return err
+}
+
+// Function enters candidate, applies operations, if needed, and executes provided command within single request.
+func (tx *Tx) exec(cmd string) (*JSONRpcResponse, error) {
+	if len(tx.ops) == 0 {
+		return nil, fmt.Errorf("transaction has no operations")
+	}
+	cmds := make([]string, 0, len(tx.ops)+2)
+	if tx.name == privateCandidate {
+		cmds = append(cmds, cmdEnterPrivate)
+	} else {
+		cmds = append(cmds, fmt.Sprintf(cmdEnterNamed, tx.name))
+	}
+	if !tx.applied {
+		cmds = append(cmds, tx.ops...)
+	}
+	cmds = append(cmds, cmd)
+
+	resp, err := ExecCliCmds(tx.t, cmds, OutFormText)
+	if err != nil {
+		return nil, err
+	}
+	if tx.name != privateCandidate {
+		tx.applied = true
+	}
+	return resp, nil
+}
+
+func (tx *Tx) reset() {
+	tx.ops = nil
+	tx.applied = false
+}
@@ -0,0 +1,97 @@
+package jrpc_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib/jrpc"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Records commands of every request and replies with text output per command.
+func recordCmds(t *testing.T, reqs *[][]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req jrpc.JSONRpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("can't decode request: %v", err)
+		}
+		if req.Params.OutFormat != jrpc.OutFormText {
+			t.Errorf("expected output format %s; got: %s", jrpc.OutFormText, req.Params.OutFormat)
+		}
+		var cmds []string
+		var result []map[string]string
+		for _, c := range req.Params.Commands {
+			cmds = append(cmds, c.(string))
+			result = append(result, map[string]string{"text": c.(string)})
+		}
+		*reqs = append(*reqs, cmds)
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}
+}
+
+func TestTxPrivate(t *testing.T) {
+	var reqs [][]string
+	tg := newTestTarget(t, recordCmds(t, &reqs))
+
+	tx := jrpc.NewTx(tg).
+		Set("system banner login-banner \"test\"").
+		Delete("interface ethernet-1/1 description")
+
+	if err := tx.Validate(); err != nil {
+		t.Fatalf("got an error from Validate(): %v", err)
+	}
+	diff, err := tx.Diff()
+	if err != nil {
+		t.Fatalf("got an error from Diff(): %v", err)
+	}
+	if diff != "diff" {
+		t.Errorf("incorrect diff: %s", diff)
+	}
+	if err := tx.Commit(5 * time.Minute); err != nil {
+		t.Fatalf("got an error from Commit(): %v", err)
+	}
+
+	ops := []string{"enter candidate private", "set / system banner login-banner \"test\"", "delete / interface ethernet-1/1 description"}
+	exp := [][]string{
+		append(append([]string{}, ops...), "commit validate"),
+		append(append([]string{}, ops...), "diff"),
+		append(append([]string{}, ops...), "commit confirmed timeout 300"),
+	}
+	if diff := cmp.Diff(exp, reqs); diff != "" {
+		t.Errorf("Tx mismatch (-exp +reqs):\n%s", diff)
+	}
+
+	if err := tx.Validate(); err == nil {
+		t.Errorf("expected an error for committed transaction w/o operations")
+	}
+}
+
+func TestTxNamed(t *testing.T) {
+	var reqs [][]string
+	tg := newTestTarget(t, recordCmds(t, &reqs))
+
+	if _, err := jrpc.NewNamedTx(tg, " "); err == nil {
+		t.Errorf("expected an error for empty candidate name")
+	}
+	tx, err := jrpc.NewNamedTx(tg, "fat")
+	if err != nil {
+		t.Fatalf("got an error from NewNamedTx(): %v", err)
+	}
+	tx.Set("system information location lab")
+	if err := tx.Validate(); err != nil {
+		t.Fatalf("got an error from Validate(): %v", err)
+	}
+	if err := tx.Discard(); err != nil {
+		t.Fatalf("got an error from Discard(): %v", err)
+	}
+
+	exp := [][]string{
+		{"enter candidate name fat", "set / system information location lab", "commit validate"},
+		{"enter candidate name fat", "discard now"},
+	}
+	if diff := cmp.Diff(exp, reqs); diff != "" {
+		t.Errorf("Tx mismatch (-exp +reqs):\n%s", diff)
+	}
+}
@@ -0,0 +1,89 @@
+package lib
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Default values used by NewRetryPolicy.
+const (
+	DefaultRetryMaxBackoff = 30 * time.Second
+	DefaultRetryMultiplier = 2.0
+	DefaultRetryJitter     = 0.2
+)
+
+// RetryPolicy defines how idempotent operations toward the target are retried.
+// Nil policy means the operation is executed only once.
+type RetryPolicy struct {
+	MaxAttempts    int           // Total number of attempts including the first one.
+	InitialBackoff time.Duration // Delay before the first retry.
+	MaxBackoff     time.Duration // Upper limit of the delay between attempts.
+	Multiplier     float64       // Backoff growth factor applied on each retry.
+	Jitter         float64       // Fraction of the delay randomized in both directions, [0,1].
+}
+
+// Creates new RetryPolicy with exponential backoff and jitter defaults.
+func NewRetryPolicy(attempts int, backoff time.Duration) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    attempts,
+		InitialBackoff: backoff,
+		MaxBackoff:     DefaultRetryMaxBackoff,
+		Multiplier:     DefaultRetryMultiplier,
+		Jitter:         DefaultRetryJitter,
+	}
+}
+
+// Function returns the delay before retry number n, starting from 1.
+func (p *RetryPolicy) Backoff(n int) time.Duration {
+	if n < 1 {
+		return 0
+	}
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 1
+	}
+	d := float64(p.InitialBackoff) * math.Pow(mult, float64(n-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		// Spreading the delay within [d*(1-jitter), d*(1+jitter)).
+		d = d * (1 + p.Jitter*(2*rand.Float64()-1))
+	}
+	return time.Duration(d)
+}
+
+// Function executes op and retries it according to the policy until it succeeds,
+// returns an error which isn't retryable or attempts are exhausted. The last error is returned.
+func (p *RetryPolicy) Do(retryable func(error) bool, op func() error) error {
+	return p.DoContext(context.Background(), retryable, op)
+}
+
+// Same as Do(), but retries are stopped, once ctx is done. Backoff is interrupted as well,
+// the last error of op is returned then.
+func (p *RetryPolicy) DoContext(ctx context.Context, retryable func(error) bool, op func() error) error {
+	attempts := 1
+	if p != nil && p.MaxAttempts > 1 {
+		attempts = p.MaxAttempts
+	}
+
+	var err error
+	for n := 0; n < attempts; n++ {
+		if n > 0 {
+			timer := time.NewTimer(p.Backoff(n))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+		err = op()
+		if err == nil || !retryable(err) {
+			return err
+		}
+	}
+	return err
+}
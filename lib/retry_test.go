@@ -0,0 +1,90 @@
+package lib_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+)
+
+var (
+	errTemporary = errors.New("temporary")
+	errPermanent = errors.New("permanent")
+)
+
+func isTemporary(err error) bool {
+	return errors.Is(err, errTemporary)
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	testData := []struct {
+		testName string
+		policy   *lib.RetryPolicy
+		errs     []error
+		expCalls int
+		expErr   error
+	}{
+		{testName: "nil policy executes once", policy: nil, errs: []error{errTemporary, nil}, expCalls: 1, expErr: errTemporary},
+		{testName: "success after retries", policy: lib.NewRetryPolicy(3, time.Millisecond), errs: []error{errTemporary, errTemporary, nil}, expCalls: 3, expErr: nil},
+		{testName: "attempts exhausted", policy: lib.NewRetryPolicy(2, time.Millisecond), errs: []error{errTemporary, errTemporary, nil}, expCalls: 2, expErr: errTemporary},
+		{testName: "non retryable error", policy: lib.NewRetryPolicy(3, time.Millisecond), errs: []error{errPermanent, nil}, expCalls: 1, expErr: errPermanent},
+	}
+
+	for _, d := range testData {
+		t.Run(d.testName, func(t *testing.T) {
+			var calls int
+			err := d.policy.Do(isTemporary, func() error {
+				err := d.errs[calls]
+				calls++
+				return err
+			})
+			if calls != d.expCalls {
+				t.Errorf("expected %d calls; got: %d", d.expCalls, calls)
+			}
+			if !errors.Is(err, d.expErr) {
+				t.Errorf("expected error: %v; got: %v", d.expErr, err)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &lib.RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+	exp := []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for n, e := range exp {
+		if b := p.Backoff(n); b != e {
+			t.Errorf("incorrect backoff for retry %d: expected %v; got: %v", n, e, b)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if b := p.Backoff(2); b < time.Second || b >= 3*time.Second {
+			t.Fatalf("backoff is out of jitter range: %v", b)
+		}
+	}
+}
+
+func TestRetryPolicyDoContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := lib.NewRetryPolicy(3, time.Hour)
+	var calls int
+	start := time.Now()
+	err := p.DoContext(ctx, isTemporary, func() error {
+		calls++
+		// Cancelling during the first attempt, so backoff is interrupted.
+		cancel()
+		return errTemporary
+	})
+	if calls != 1 {
+		t.Errorf("expected 1 call; got: %d", calls)
+	}
+	if !errors.Is(err, errTemporary) {
+		t.Errorf("expected error: %v; got: %v", errTemporary, err)
+	}
+	if time.Since(start) > time.Minute {
+		t.Errorf("backoff isn't interrupted by cancelled context")
+	}
+}
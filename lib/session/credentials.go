@@ -0,0 +1,34 @@
+package session
+
+import (
+	"context"
+
+	"github.com/azyablov/fat/lib"
+)
+
+// perRPCCredentials attaches username and password metadata to every RPC, the same way gnmilib.PopulateMDCredentials does.
+type perRPCCredentials struct {
+	username string
+	password string
+	insecure bool
+}
+
+func newPerRPCCredentials(t *lib.SRLTarget) *perRPCCredentials {
+	return &perRPCCredentials{
+		username: *t.Username,
+		password: *t.Password,
+		insecure: *t.InsecConn && !*t.SkipVerify,
+	}
+}
+
+func (c *perRPCCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{
+		"username": c.username,
+		"password": c.password,
+	}, nil
+}
+
+// Credentials are allowed over insecure connection only, if it was requested explicitly.
+func (c *perRPCCredentials) RequireTransportSecurity() bool {
+	return !c.insecure
+}
@@ -0,0 +1,32 @@
+package session
+
+import (
+	"errors"
+
+	"github.com/azyablov/fat/lib"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Function returns true, if gRPC call failed due to target unavailability, e.g. connection refused
+// while the target is still booting, so idempotent call can be retried.
+func Retryable(err error) bool {
+	var s interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &s) {
+		return s.GRPCStatus().Code() == codes.Unavailable
+	}
+	return false
+}
+
+// Function marks error of gRPC call as lib.UnavailableError, if the target can't be reached or the user isn't
+// permitted to use the service, so config extraction could fall back to another transport.
+func SourceError(err error) error {
+	var s interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &s) {
+		switch s.GRPCStatus().Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.PermissionDenied, codes.Unauthenticated:
+			return &lib.UnavailableError{Err: err}
+		}
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+// Package session provides gRPC connection to the target shared by gNOI and gNMI helpers.
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/gnmi-pg/gnmilib"
+	"google.golang.org/grpc"
+)
+
+// Session owns gRPC connection to the target, credentials are attached to every RPC.
+// Session is safe for concurrent use and should be closed once not needed.
+type Session struct {
+	t    *lib.SRLTarget
+	conn *grpc.ClientConn
+}
+
+// Creates session to the gNOI port of the target.
+func New(t *lib.SRLTarget) (*Session, error) {
+	return NewWithPort(t, *t.PortgNOI)
+}
+
+// Creates session to the specified port of the target, e.g. gNMI one.
+// If target hostname contains port already, it takes precedence.
+func NewWithPort(t *lib.SRLTarget, port int) (*Session, error) {
+	dOpts, err := dialOptions(t)
+	if err != nil {
+		return nil, err
+	}
+	// Credentials are injected by the connection, so callers don't need to populate context metadata.
+	dOpts = append(dOpts, grpc.WithPerRPCCredentials(newPerRPCCredentials(t)))
+
+	// Set up a connection to the server.
+	var host string // target address to connect using grpc.Dial()
+	if strings.Contains(*t.Hostname, ":") {
+		host = *t.Hostname
+	} else {
+		host = fmt.Sprintf("%s:%v", *t.Hostname, port)
+	}
+
+	// Dialing and getting gRPC connection.
+	conn, err := grpc.Dial(host, dOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("can't not connect to the host %s due to %s", *t.Hostname, err)
+	}
+	return &Session{t: t, conn: conn}, nil
+}
+
+// Function creates session to the specified port of the target, calls fn over it and closes the session.
+func WithSession(t *lib.SRLTarget, port int, fn func(s *Session) error) error {
+	s, err := NewWithPort(t, port)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return fn(s)
+}
+
+// Returns gRPC connection to create service clients.
+func (s *Session) Conn() *grpc.ClientConn {
+	return s.conn
+}
+
+// Returns the target session is established to.
+func (s *Session) Target() *lib.SRLTarget {
+	return s.t
+}
+
+// Returns context bound to the target timeout.
+func (s *Session) Context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), *s.t.Timeout)
+}
+
+// Closes gRPC connection.
+func (s *Session) Close() error {
+	return s.conn.Close()
+}
+
+// Function returns TLS dial options according to the target attributes.
+func dialOptions(t *lib.SRLTarget) ([]grpc.DialOption, error) {
+	tlsInit := gnmilib.TLSInit{
+		SkipVerify:     *t.SkipVerify,
+		TargetHostname: *t.Hostname,
+	}
+	// Insecure connection, CA and client certificate are out of use, if verification is skipped.
+	if !*t.SkipVerify {
+		tlsInit.InsecConn = *t.InsecConn
+		tlsInit.RootCA = *t.RootCA
+		tlsInit.Cert = *t.Cert
+		tlsInit.Key = *t.Key
+	}
+	dOpts, err := gnmilib.SetupGNMISecureTransport(tlsInit)
+	if err != nil {
+		return nil, fmt.Errorf("setting up grpc options: %s", err)
+	}
+	return *dOpts, nil
+}
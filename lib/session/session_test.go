@@ -0,0 +1,54 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+)
+
+func newTestTarget(insecConn bool, skipVerify bool) *lib.SRLTarget {
+	username, password, hostname := "admin", "NokiaSrl1!", "srl1"
+	port := 57400
+	timeout := 5 * time.Second
+	tg := new(lib.SRLTarget)
+	tg.Hostname = &hostname
+	tg.PortgNOI = &port
+	tg.Username = &username
+	tg.Password = &password
+	tg.Timeout = &timeout
+	tg.InsecConn = &insecConn
+	tg.SkipVerify = &skipVerify
+	return tg
+}
+
+func TestPerRPCCredentials(t *testing.T) {
+	testData := []struct {
+		testName   string
+		insecConn  bool
+		skipVerify bool
+		expSec     bool
+	}{
+		{testName: "TLS", expSec: true},
+		{testName: "TLS w/o verification", skipVerify: true, expSec: true},
+		{testName: "Insecure connection", insecConn: true, expSec: false},
+		{testName: "Insecure connection ignored with skip verify", insecConn: true, skipVerify: true, expSec: true},
+	}
+
+	for _, d := range testData {
+		t.Run(d.testName, func(t *testing.T) {
+			c := newPerRPCCredentials(newTestTarget(d.insecConn, d.skipVerify))
+			md, err := c.GetRequestMetadata(context.Background())
+			if err != nil {
+				t.Fatalf("got an error from GetRequestMetadata(): %v", err)
+			}
+			if md["username"] != "admin" || md["password"] != "NokiaSrl1!" {
+				t.Errorf("incorrect metadata: %v", md)
+			}
+			if c.RequireTransportSecurity() != d.expSec {
+				t.Errorf("expected RequireTransportSecurity: %v; got: %v", d.expSec, c.RequireTransportSecurity())
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Package sessiontest provides in-memory gRPC connection to fake servers for tests of gNOI and gNMI helpers.
+package sessiontest
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Starts gRPC server with the services registered by register and returns connection to it.
+// Server and connection are stopped once the test is finished.
+func NewConn(t *testing.T, register func(s *grpc.Server)) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	register(s)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("can't dial fake server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// Returns target with test credentials and timeout.
+func NewTarget() *lib.SRLTarget {
+	username, password := "admin", "admin"
+	timeout := 5 * time.Second
+	tg := new(lib.SRLTarget)
+	tg.Username = &username
+	tg.Password = &password
+	tg.Timeout = &timeout
+	return tg
+}
@@ -0,0 +1,324 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Formats of extracted config.
+const (
+	ConfigFormatInfo = "info" // info text, as `info` command outputs it
+	ConfigFormatJSON = "json" // JSON_IETF tree
+)
+
+// SystemVersion identifies the target the config is extracted from.
+type SystemVersion struct {
+	Hostname        string
+	ChassisType     string
+	SoftwareVersion string // e.g. v23.3.1
+}
+
+// Parses text output of `show version`, lines are "<field> : <value>".
+func ParseShowVersion(out string) (*SystemVersion, error) {
+	v := new(SystemVersion)
+	for _, l := range strings.Split(out, "\n") {
+		i := strings.Index(l, ":")
+		if i == -1 {
+			continue
+		}
+		val := strings.TrimSpace(l[i+1:])
+		switch strings.TrimSpace(l[:i]) {
+		case "Hostname":
+			v.Hostname = val
+		case "Chassis Type":
+			v.ChassisType = val
+		case "Software Version":
+			v.SoftwareVersion = val
+		}
+	}
+	if v.Hostname == "" || v.SoftwareVersion == "" {
+		return nil, fmt.Errorf("hostname or software version is missed in show version output")
+	}
+	return v, nil
+}
+
+// ConfigSource extracts config of the target over particular transport.
+type ConfigSource interface {
+	ShowVersion() (*SystemVersion, error) // hostname, chassis type and software version of the target
+	Info() (string, error)                // running config in Format()
+	Format() string                       // ConfigFormatInfo or ConfigFormatJSON
+	Cleanup() error                       // removes temporary artifacts on the target and releases the connection
+}
+
+// UnavailableError is returned by config sources, if the transport can't be used, e.g. connection is refused,
+// authentication fails or the user isn't permitted to use it, so the next transport could be tried.
+type UnavailableError struct {
+	Err error
+}
+
+func (e *UnavailableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// Returns true, if err means the transport is unavailable: UnavailableError, network error, e.g. connection refused,
+// or permission denied by the target.
+func IsUnavailable(err error) bool {
+	var uErr *UnavailableError
+	var nErr net.Error
+	return errors.As(err, &uErr) || errors.As(err, &nErr) || strings.Contains(err.Error(), "Permission denied")
+}
+
+// CommandRunner is implemented by config sources able to execute CLI commands, e.g. SSH and JSON-RPC ones.
+type CommandRunner interface {
+	RunCLI(cmd string) (string, error)
+}
+
+// ConfigSourceFunc opens config source toward the target.
+// Calls of the source are aborted once ctx is done, the source still has to be cleaned up then.
+type ConfigSourceFunc func(ctx context.Context, t *SRLTarget) (ConfigSource, error)
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = make(map[string]ConfigSourceFunc)
+)
+
+// Registers config source under the transport name, usually from init() of the package implementing it.
+// Panics if the name is registered twice.
+func RegisterConfigSource(name string, fn ConfigSourceFunc) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if _, ok := sources[name]; ok {
+		panic("config source " + name + " is registered twice")
+	}
+	sources[name] = fn
+}
+
+// Sources maps transport names to config sources opened per call, e.g. ones with options differing from registered defaults
+// or ones depending on another transport. Transports missed are looked up among registered sources.
+type Sources map[string]ConfigSourceFunc
+
+// Returns sorted names of registered config sources.
+func ConfigSources() []string {
+	return Sources(nil).Names()
+}
+
+// Returns an error, if any of the transports isn't registered.
+func CheckConfigSources(transports ...string) error {
+	return Sources(nil).Check(transports...)
+}
+
+// Opens config source registered under the transport name.
+func OpenConfigSource(ctx context.Context, t *SRLTarget, name string) (ConfigSource, error) {
+	return Sources(nil).Open(ctx, t, name)
+}
+
+// Returns sorted names of the sources and registered ones.
+func (s Sources) Names() []string {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	names := make([]string, 0, len(sources)+len(s))
+	for n := range sources {
+		names = append(names, n)
+	}
+	for n := range s {
+		if _, ok := sources[n]; !ok {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Returns an error, if any of the transports is neither in the sources nor registered.
+func (s Sources) Check(transports ...string) error {
+	for _, name := range transports {
+		if _, ok := s.lookup(name); !ok {
+			return fmt.Errorf("unknown transport %s", name)
+		}
+	}
+	return nil
+}
+
+// Opens config source of the transport, the one of the sources takes precedence over registered one.
+func (s Sources) Open(ctx context.Context, t *SRLTarget, name string) (ConfigSource, error) {
+	fn, ok := s.lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown transport %s, known: %s", name, strings.Join(s.Names(), ", "))
+	}
+	return fn(ctx, t)
+}
+
+// Function returns source of the transport, the one of s takes precedence over registered one.
+func (s Sources) lookup(name string) (ConfigSourceFunc, bool) {
+	if fn, ok := s[name]; ok {
+		return fn, true
+	}
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	fn, ok := sources[name]
+	return fn, ok
+}
+
+// Extraction is the config extracted from the target.
+type Extraction struct {
+	Transport string // name of the transport config is extracted over
+	Version   *SystemVersion
+	Config    string
+	Format    string   // ConfigFormatInfo or ConfigFormatJSON
+	Failed    []string // transports tried before the successful one with their errors, "<name>: <error>"
+}
+
+// Extracts config over the first transport available, the rest ones are tried in order if preferred one is unavailable,
+// e.g. connection is refused or the user isn't permitted to use the transport, see IsUnavailable().
+// Other errors, e.g. incomplete output of the target, are returned at once.
+// Extraction is aborted once ctx is done, returns after the source in use is cleaned up.
+func ExtractConfig(ctx context.Context, t *SRLTarget, transports ...string) (*Extraction, error) {
+	return Sources(nil).ExtractConfig(ctx, t, transports...)
+}
+
+// Same as ExtractConfig(), but transports are opened via the sources, registered ones are used for the rest.
+func (s Sources) ExtractConfig(ctx context.Context, t *SRLTarget, transports ...string) (*Extraction, error) {
+	if len(transports) == 0 {
+		return nil, fmt.Errorf("no transports to extract config over")
+	}
+	err := s.Check(transports...)
+	if err != nil {
+		return nil, err
+	}
+	var errs []string
+	for _, name := range transports {
+		if ctx.Err() != nil {
+			// No fallback, once time is over.
+			errs = append(errs, fmt.Sprintf("%s: %s", name, ctx.Err()))
+			break
+		}
+		e, err := s.extract(ctx, t, name)
+		if err == nil {
+			e.Failed = errs
+			return e, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", name, err))
+		if !IsUnavailable(err) {
+			break
+		}
+	}
+	return nil, fmt.Errorf("can't extract config: %s", strings.Join(errs, "; "))
+}
+
+// Function extracts config over the transport, source is cleaned up in any case.
+// Cleanup failure doesn't invalidate extracted config, so it's only logged.
+func (s Sources) extract(ctx context.Context, t *SRLTarget, name string) (*Extraction, error) {
+	src, err := s.Open(ctx, t, name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := src.Cleanup()
+		if err != nil {
+			log.Printf("can't clean up %s config source: %s", name, err)
+		}
+	}()
+
+	v, err := src.ShowVersion()
+	if err != nil {
+		return nil, err
+	}
+	// Both are components of backup file names.
+	if v == nil || v.Hostname == "" || v.SoftwareVersion == "" {
+		return nil, fmt.Errorf("hostname or software version is missed in %s output", name)
+	}
+	cfg, err := src.Info()
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(cfg)) == 0 {
+		return nil, fmt.Errorf("empty config received")
+	}
+	return &Extraction{Transport: name, Version: v, Config: cfg, Format: src.Format()}, nil
+}
+
+// Calls fn in its own goroutine once ctx is done, as context.AfterFunc() of Go 1.21 does, e.g. to close the connection
+// blocked call is waiting on. Returned stop prevents fn from being called, it returns false, if fn is called already,
+// and waits for fn to return then. stop has to be called to release the goroutine.
+func AfterFunc(ctx context.Context, fn func()) (stop func() bool) {
+	var once sync.Once
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			once.Do(fn)
+		case <-stopped:
+		}
+	}()
+	return func() bool {
+		called := true
+		once.Do(func() {
+			called = false
+			close(stopped)
+		})
+		return !called
+	}
+}
+
+// Removes containerlab artifacts from extracted config, see CleanUpClabInfoObjects() and CleanUpClabJSON().
+// Interfaces clab enables for the endpoints of the node, e.g. ethernet-1/1, are removed as well, see CleanUpClabEndpoints().
+func (e *Extraction) CleanUpClab(endpoints ...string) error {
+	switch e.Format {
+	case ConfigFormatInfo:
+		root, err := NewInfoObject(e.Config)
+		if err != nil {
+			return err
+		}
+		cfg, err := CleanUpClabInfoObjects(root, e.Config)
+		if err != nil {
+			return err
+		}
+		if len(endpoints) != 0 {
+			root, err = NewInfoObject(cfg)
+			if err != nil {
+				return err
+			}
+			cfg, err = CleanUpClabEndpoints(root, cfg, endpoints)
+			if err != nil {
+				return err
+			}
+		}
+		e.Config = cfg
+	case ConfigFormatJSON:
+		d := json.NewDecoder(strings.NewReader(e.Config))
+		d.UseNumber()
+		var cfg map[string]any
+		err := d.Decode(&cfg)
+		if err != nil {
+			return fmt.Errorf("can't decode config: %s", err)
+		}
+		err = CleanUpClabJSON(cfg)
+		if err != nil {
+			return err
+		}
+		CleanUpClabEndpointsJSON(cfg, endpoints)
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(cfg)
+		if err != nil {
+			return fmt.Errorf("can't encode config: %s", err)
+		}
+		e.Config = b.String()
+	default:
+		return fmt.Errorf("unknown config format %s", e.Format)
+	}
+	return nil
+}
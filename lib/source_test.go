@@ -0,0 +1,183 @@
+package lib_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeSource returns fixed config or fails on the step configured.
+type fakeSource struct {
+	failOn  string
+	cleaned *[]string
+	name    string
+}
+
+func (s *fakeSource) ShowVersion() (*lib.SystemVersion, error) {
+	if s.failOn == "version" {
+		return nil, &lib.UnavailableError{Err: fmt.Errorf("version unavailable")}
+	}
+	if s.failOn == "hostname" {
+		return &lib.SystemVersion{SoftwareVersion: "v23.3.1"}, nil
+	}
+	return &lib.SystemVersion{Hostname: "leaf1", ChassisType: "7220 IXR-D2", SoftwareVersion: "v23.3.1"}, nil
+}
+
+func (s *fakeSource) Info() (string, error) {
+	if s.failOn == "info" {
+		return "", fmt.Errorf("permission denied: Permission denied")
+	}
+	return "system {\n}\n", nil
+}
+
+func (s *fakeSource) Format() string {
+	return lib.ConfigFormatInfo
+}
+
+func (s *fakeSource) Cleanup() error {
+	*s.cleaned = append(*s.cleaned, s.name)
+	return nil
+}
+
+func TestExtractConfig(t *testing.T) {
+	var cleaned []string
+	register := func(name string, failOn string, openErr error) {
+		lib.RegisterConfigSource(name, func(ctx context.Context, t *lib.SRLTarget) (lib.ConfigSource, error) {
+			if openErr != nil {
+				return nil, openErr
+			}
+			return &fakeSource{failOn: failOn, cleaned: &cleaned, name: name}, nil
+		})
+	}
+	register("test-down", "", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})
+	register("test-noinfo", "info", nil)
+	register("test-nohost", "hostname", nil)
+	register("test-ok", "", nil)
+
+	e, err := lib.ExtractConfig(context.Background(), new(lib.SRLTarget), "test-down", "test-noinfo", "test-ok")
+	if err != nil {
+		t.Fatalf("got an error from ExtractConfig(): %v", err)
+	}
+	want := &lib.Extraction{
+		Transport: "test-ok",
+		Version:   &lib.SystemVersion{Hostname: "leaf1", ChassisType: "7220 IXR-D2", SoftwareVersion: "v23.3.1"},
+		Config:    "system {\n}\n",
+		Format:    lib.ConfigFormatInfo,
+		Failed:    []string{"test-down: dial tcp: connection refused", "test-noinfo: permission denied: Permission denied"},
+	}
+	if diff := cmp.Diff(want, e); diff != "" {
+		t.Errorf("ExtractConfig() mismatch (-want +got):\n%s", diff)
+	}
+	if err := lib.CheckConfigSources("test-ok", "test-unknown"); err == nil {
+		t.Errorf("expected an error for unknown transport")
+	}
+	if diff := cmp.Diff([]string{"test-noinfo", "test-ok"}, cleaned); diff != "" {
+		t.Errorf("opened sources aren't cleaned up (-want +got):\n%s", diff)
+	}
+
+	// Only unavailable transport is fallen back from.
+	_, err = lib.ExtractConfig(context.Background(), new(lib.SRLTarget), "test-down", "test-nohost", "test-ok")
+	if err == nil || !strings.Contains(err.Error(), "connection refused") || !strings.Contains(err.Error(), "hostname or software version is missed") || strings.Contains(err.Error(), "test-ok") {
+		t.Errorf("expected extraction to stop at test-nohost; got: %v", err)
+	}
+
+	// Unknown transports are rejected before any is tried.
+	_, err = lib.ExtractConfig(context.Background(), new(lib.SRLTarget), "test-down", "test-unknown")
+	if err == nil || strings.Contains(err.Error(), "connection refused") || !strings.Contains(err.Error(), "unknown transport test-unknown") {
+		t.Errorf("expected unknown transport error only; got: %v", err)
+	}
+
+	// Sources take precedence over registered ones, the rest are still registered.
+	srcs := lib.Sources{
+		"test-down": func(ctx context.Context, t *lib.SRLTarget) (lib.ConfigSource, error) {
+			return &fakeSource{cleaned: &cleaned, name: "test-up"}, nil
+		},
+		"test-extra": func(ctx context.Context, t *lib.SRLTarget) (lib.ConfigSource, error) {
+			return nil, &lib.UnavailableError{Err: fmt.Errorf("unavailable")}
+		},
+	}
+	e, err = srcs.ExtractConfig(context.Background(), new(lib.SRLTarget), "test-extra", "test-down")
+	if err != nil || e.Transport != "test-down" {
+		t.Errorf("expected config extracted via test-down source; got: %+v, %v", e, err)
+	}
+	if err := srcs.Check("test-ok", "test-extra"); err != nil {
+		t.Errorf("got an error from Check(): %v", err)
+	}
+	if err := lib.CheckConfigSources("test-extra"); err == nil {
+		t.Errorf("expected sources not to be registered")
+	}
+
+	// No fallback, once context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = lib.ExtractConfig(ctx, new(lib.SRLTarget), "test-ok")
+	if !strings.Contains(fmt.Sprint(err), "test-ok: context canceled") {
+		t.Errorf("expected extraction to be aborted; got: %v", err)
+	}
+}
+
+func TestAfterFunc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	called := make(chan struct{})
+	stop := lib.AfterFunc(ctx, func() { close(called) })
+	cancel()
+	<-called
+	if stop() {
+		t.Errorf("expected stop to report fn called")
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	stop = lib.AfterFunc(ctx, func() { t.Errorf("fn is called after stop") })
+	if !stop() {
+		t.Errorf("expected stop to prevent fn from being called")
+	}
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestExtractionCleanUpClab(t *testing.T) {
+	e := &lib.Extraction{
+		Format: lib.ConfigFormatJSON,
+		Config: `{"srl_nokia-system:system": {"srl_nokia-system-banner:banner": {"login-banner": "clab"}, "name": {"host-name": "leaf1"}}}`,
+	}
+	err := e.CleanUpClab()
+	if err != nil {
+		t.Fatalf("got an error from CleanUpClab(): %v", err)
+	}
+	want := "{\n  \"srl_nokia-system:system\": {\n    \"name\": {\n      \"host-name\": \"leaf1\"\n    }\n  }\n}\n"
+	if diff := cmp.Diff(want, e.Config); diff != "" {
+		t.Errorf("CleanUpClab() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseShowVersion(t *testing.T) {
+	out := `-----------------------------------------------------------------------
+Hostname             : leaf1
+Chassis Type         : 7220 IXR-D2
+Part Number          : Sim Part No.
+System HW MAC Address: 1A:B0:00:FF:00:00
+Software Version     : v23.3.1
+Build Number         : 343-gab924f2e64
+Last Booted          : 2023-04-13T10:02:58.571Z
+-----------------------------------------------------------------------
+`
+	v, err := lib.ParseShowVersion(out)
+	if err != nil {
+		t.Fatalf("got an error from ParseShowVersion(): %v", err)
+	}
+	want := &lib.SystemVersion{Hostname: "leaf1", ChassisType: "7220 IXR-D2", SoftwareVersion: "v23.3.1"}
+	if diff := cmp.Diff(want, v); diff != "" {
+		t.Errorf("ParseShowVersion() mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := lib.ParseShowVersion("Hostname : leaf1\n"); err == nil {
+		t.Errorf("expected an error for missed software version")
+	}
+}
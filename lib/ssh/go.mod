@@ -0,0 +1,18 @@
+module github.com/azyablov/fat/lib/ssh
+
+go 1.18
+
+require (
+	github.com/azyablov/fat/lib v0.0.0-20230317114747-14ecb7a2410e
+	github.com/scrapli/scrapligo v1.1.7
+)
+
+require (
+	github.com/creack/pty v1.1.18 // indirect
+	github.com/sirikothe/gotextfsm v1.0.1-0.20200816110946-6aa2cfd355e4 // indirect
+	golang.org/x/crypto v0.6.0 // indirect
+	golang.org/x/sys v0.5.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/azyablov/fat/lib => ../
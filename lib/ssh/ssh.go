@@ -0,0 +1,114 @@
+// Package ssh extracts config of SR Linux target over SSH CLI session using scrapligo.
+package ssh
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azyablov/fat/lib"
+	"github.com/scrapli/scrapligo/driver/network"
+	"github.com/scrapli/scrapligo/driver/options"
+	"github.com/scrapli/scrapligo/platform"
+	"github.com/scrapli/scrapligo/util"
+)
+
+// Name of the transport config source is registered under.
+const SourceName = "ssh"
+
+func init() {
+	lib.RegisterConfigSource(SourceName, NewConfigSource)
+}
+
+// ConfigSource extracts info config via CLI commands sent over SSH.
+type ConfigSource struct {
+	d    *network.Driver
+	stop func() bool // stops closing the connection, once ctx is done
+}
+
+// Creates config source over new SSH connection to the target, command in flight is aborted
+// by closing the connection, once ctx is done.
+func NewConfigSource(ctx context.Context, t *lib.SRLTarget) (lib.ConfigSource, error) {
+	return NewConfigSourceFunc()(ctx, t)
+}
+
+// Returns function opening config source with the options applied to every connection, e.g. options.WithDefaultLogger(),
+// to be used via lib.Sources.
+func NewConfigSourceFunc(opts ...util.Option) lib.ConfigSourceFunc {
+	return func(ctx context.Context, t *lib.SRLTarget) (lib.ConfigSource, error) {
+		d, err := Open(t, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &ConfigSource{d: d, stop: lib.AfterFunc(ctx, func() { d.Close() })}, nil
+	}
+}
+
+// Opens SSH connection to the target, caller closes the driver. Connection failure is reported as lib.UnavailableError.
+// Scrapligo logging is enabled, if opts contain options.WithDefaultLogger() or another logger.
+func Open(t *lib.SRLTarget, opts ...util.Option) (*network.Driver, error) {
+	opts = append([]util.Option(nil), opts...)
+	if t.NoStrictKey != nil && *t.NoStrictKey {
+		opts = append(opts, options.WithAuthNoStrictKey())
+	}
+	opts = append(opts,
+		options.WithAuthPassword(*t.Password),
+		options.WithAuthUsername(*t.Username),
+	)
+	if t.PortSSH != nil {
+		opts = append(opts, options.WithPort(*t.PortSSH))
+	}
+
+	p, err := platform.NewPlatform(platform.NokiaSrl, *t.Hostname, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create platform: %s", err)
+	}
+	d, err := p.GetNetworkDriver()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver: %s", err)
+	}
+	err = d.Open()
+	if err != nil {
+		return nil, &lib.UnavailableError{Err: fmt.Errorf("failed to open SSH connection toward %s: %s", *t.Hostname, err)}
+	}
+	return d, nil
+}
+
+// Returns hostname, chassis type and software version parsed from `show version`.
+func (s *ConfigSource) ShowVersion() (*lib.SystemVersion, error) {
+	out, err := s.RunCLI("show version")
+	if err != nil {
+		return nil, err
+	}
+	return lib.ParseShowVersion(out)
+}
+
+// Returns running config as `info` outputs it.
+func (s *ConfigSource) Info() (string, error) {
+	return s.RunCLI("info")
+}
+
+// Returns lib.ConfigFormatInfo.
+func (s *ConfigSource) Format() string {
+	return lib.ConfigFormatInfo
+}
+
+// Sends CLI command and returns its output, failed commands are reported as errors.
+func (s *ConfigSource) RunCLI(cmd string) (string, error) {
+	r, err := s.d.SendCommand(cmd)
+	if err != nil {
+		// Connection is broken, e.g. closed by the target.
+		return "", &lib.UnavailableError{Err: fmt.Errorf("failed to send command %q: %s", cmd, err)}
+	}
+	if r.Failed != nil {
+		return "", fmt.Errorf("command %q failed: %s", cmd, r.Failed)
+	}
+	return r.Result, nil
+}
+
+// Closes SSH connection, unless it's closed already on ctx done.
+func (s *ConfigSource) Cleanup() error {
+	if !s.stop() {
+		return nil
+	}
+	return s.d.Close()
+}
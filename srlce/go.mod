@@ -4,8 +4,12 @@ go 1.18
 
 require (
 	github.com/azyablov/fat/lib v0.0.0-20230317114747-14ecb7a2410e
+	github.com/azyablov/fat/lib/gnmi v0.0.0-20230317114747-14ecb7a2410e
 	github.com/azyablov/fat/lib/gnoi/file v0.0.0-20230317114747-14ecb7a2410e
+	github.com/azyablov/fat/lib/inventory v0.0.0-20230317114747-14ecb7a2410e
 	github.com/azyablov/fat/lib/jrpc v0.0.0-20230317114747-14ecb7a2410e
+	github.com/azyablov/fat/lib/session v0.0.0-20230317114747-14ecb7a2410e
+	github.com/azyablov/fat/lib/ssh v0.0.0-20230317114747-14ecb7a2410e
 	github.com/scrapli/scrapligo v1.1.7
 	github.com/sirupsen/logrus v1.9.0
 )
@@ -14,6 +18,7 @@ require (
 	github.com/azyablov/gnmi-pg/gnmilib v0.0.0-20230307170529-c2aceddccaa0 // indirect
 	github.com/creack/pty v1.1.18 // indirect
 	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/openconfig/gnmi v0.9.1 // indirect
 	github.com/openconfig/gnoi v0.0.0-20230221223856-1727ed932554 // indirect
 	github.com/sirikothe/gotextfsm v1.0.1-0.20200816110946-6aa2cfd355e4 // indirect
 	golang.org/x/crypto v0.6.0 // indirect
@@ -25,3 +30,13 @@ require (
 	google.golang.org/protobuf v1.28.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace (
+	github.com/azyablov/fat/lib => ../lib
+	github.com/azyablov/fat/lib/gnmi => ../lib/gnmi
+	github.com/azyablov/fat/lib/gnoi/file => ../lib/gnoi/file
+	github.com/azyablov/fat/lib/inventory => ../lib/inventory
+	github.com/azyablov/fat/lib/jrpc => ../lib/jrpc
+	github.com/azyablov/fat/lib/session => ../lib/session
+	github.com/azyablov/fat/lib/ssh => ../lib/ssh
+)
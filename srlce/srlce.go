@@ -1,20 +1,34 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"io/ioutil"
+	"net/url"
 	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"text/template"
 	"time"
 
 	"github.com/azyablov/fat/lib"
+	"github.com/azyablov/fat/lib/gnmi"
 	"github.com/azyablov/fat/lib/gnoi/file"
+	"github.com/azyablov/fat/lib/inventory"
 	"github.com/azyablov/fat/lib/jrpc"
+	"github.com/azyablov/fat/lib/session"
+	"github.com/azyablov/fat/lib/ssh"
 	"github.com/scrapli/scrapligo/driver/options"
-	"github.com/scrapli/scrapligo/platform"
-	"github.com/scrapli/scrapligo/response"
 	"github.com/scrapli/scrapligo/util"
 	log "github.com/sirupsen/logrus"
 )
@@ -24,14 +38,47 @@ type cliOpt struct {
 	jsonRPC           *bool
 	cleanUpClabConfig *bool
 	gNOIdld           *bool
+	gNMI              *bool
+	transports        *string
+	retries           *int
+	retryBackoff      *time.Duration
 	rFile             *string
+	push              *string
+	pushDir           *string
+	pushPerm          *string
+	pull              *string
+	restore           *string
+	restorePaths      *string
+	uploadTo          *string
+	transferTimeout   *time.Duration
 	logFile           *string
 	d                 *bool
 	logSSH            *bool
+	watch             *bool
+	auditLog          *string
+	inventory         *string
+	workers           *int
+	targetTimeout     *time.Duration
+	snapshot          *string
+	outDir            *string
+	fileName          *string
+	keep              *int
+	keepFor           *time.Duration
+	nameTmpl          *template.Template // parsed -fileName
 }
 
-type showVersion map[string]string
-type jsonOutText map[string]string
+// Default template of config file name, extension is appended to the name.
+// Timestamp keeps the previous backups, so they accumulate and -keep/-keepFor apply.
+const defaultFileName = "{{.Hostname}}_{{.SoftwareVersion}}_{{.Timestamp}}"
+
+// Fields of config file name template.
+type fileNameData struct {
+	Hostname        string
+	SoftwareVersion string
+	ChassisType     string
+	Transport       string
+	Timestamp       string // 20060102T150405
+}
 
 func main() {
 	// Init and parse flags
@@ -40,19 +87,43 @@ func main() {
 	f.jsonRPC = flag.Bool("jsonrpc", false, "Use JSON RPC instead of SSH")
 	f.cleanUpClabConfig = flag.Bool("cclab", false, "Clean up clab generated config")
 	f.gNOIdld = flag.Bool("gNOIdld", false, "Use gNOI to download info config")
-	f.rFile = flag.String("rFile", "myconfig.cfg", "Remote file name on target NE")
+	f.gNMI = flag.Bool("gNMI", false, "Use gNMI Get to retrieve config in JSON_IETF encoding, saved as JSON")
+	f.transports = flag.String("transports", "", "Comma separated list of transports (gnoi, jsonrpc, ssh, gnmi) tried in order till config is extracted, e.g. gnoi,jsonrpc,ssh; overrides -jsonrpc, -gNOIdld and -gNMI")
+	f.retries = flag.Int("retries", 1, "Number of attempts for idempotent JSON RPC and gNOI calls")
+	f.retryBackoff = flag.Duration("retryBackoff", time.Second, "Initial backoff between retries, grows exponentially with jitter")
+	f.rFile = flag.String("rFile", "myconfig.cfg", "Remote file name on target NE, used with -uploadTo")
+	f.push = flag.String("push", "", "Upload specified local file (saved or tfsm processed config) to the target via gNOI and exit")
+	f.pushDir = flag.String("pushDir", "/etc/opt/srlinux/", "Remote directory to upload file into")
+	f.pushPerm = flag.String("pushPerm", "0644", "Remote file permissions in octal format")
+	f.pull = flag.String("pull", "", "Download all files of specified remote directory (e.g. /etc/opt/srlinux/checkpoint) via gNOI into local one and exit")
+	f.restore = flag.String("restore", "", "Restore config from specified backup file (info or JSON one) via gNMI Set and exit")
+	f.restorePaths = flag.String("restorePaths", "", "Comma separated list of paths to restore, e.g. /system/tls,/interface[name=ethernet-1/1], the whole config by default")
+	f.uploadTo = flag.String("uploadTo", "", "Instruct the target to upload config via gNOI to remote directory URL (scp, sftp, http, https) instead of downloading it, used with -gNOIdld")
+	f.transferTimeout = flag.Duration("transferTimeout", 10*time.Minute, "Time limit of the whole config transfer to remote URL, used with -uploadTo")
 	f.logFile = flag.String("logFile", "", "Log all messages into specified log file instead of stderr")
 	f.d = flag.Bool("d", false, "Enable debug, by default warn")
 	f.logSSH = flag.Bool("logSSH", false, "Enable SSH debug, by default disabled")
+	f.watch = flag.Bool("watch", false, "Watch config commits via gNMI Subscribe and save timestamped backup on every commit, till interrupted")
+	f.auditLog = flag.String("auditLog", "", "Append commit audit records (JSON lines) into specified file, used with -watch; <outDir>/audit.log by default")
+
+	f.outDir = flag.String("outDir", ".", "Local directory to save configs into, incl. backups of -watch")
+	f.fileName = flag.String("fileName", defaultFileName, "Go template of config file name w/o extension, fields: Hostname, SoftwareVersion, ChassisType, Transport, Timestamp")
+	f.keep = flag.Int("keep", 0, "Number of the latest configs of the target to keep in the directory, older ones are removed; 0 keeps all")
+	f.keepFor = flag.Duration("keepFor", 0, "Remove configs of the target older than specified duration from the directory, e.g. 720h; 0 keeps all")
+	f.inventory = flag.String("inventory", "", "Back up all targets of YAML (.yml, .yaml) or CSV (.csv) inventory, or SR Linux nodes of containerlab topology (topology.clab.yml, topology-data.json) concurrently, host fields override target flags")
+	f.workers = flag.Int("workers", 8, "Number of targets backed up concurrently, used with -inventory or multiple targets")
+	f.snapshot = flag.String("snapshot", "", "Save cleaned configs of clab nodes into <dir>/configs/<node>.cli (.json for gNMI) and the topology referencing them via startup-config into <dir>, used with clab topology file as -inventory")
+	f.targetTimeout = flag.Duration("targetTimeout", 2*time.Minute, "Time limit of single target backup, used with -inventory or multiple targets")
 
 	t := new(lib.SRLTarget)
 	t.Username = flag.String("username", "admin", "SSH username")
 	t.Password = flag.String("password", "NokiaSrl1!", "SSH password")
 	t.NoStrictKey = flag.Bool("noSKey", true, "No SSH key checking")
-	t.Hostname = flag.String("target", "", "Target hostname")
+	t.Hostname = flag.String("target", "", "Target hostname, or comma separated list of hostname patterns to back up concurrently, e.g. leaf{1..4},spine{1,2}")
 	t.PortSSH = flag.Int("SSHport", 22, "SSH port")
 	t.PortJRpc = flag.Int("JRPCport", 443, "JSON RPC port")
 	t.PortgNOI = flag.Int("gNOIport", 57400, "gNOI port")
+	t.PortgNMI = flag.Int("gNMIport", 57400, "gNMI port")
 	t.Timeout = flag.Duration("timeout", 10*time.Second, "Connection timeout")
 	t.InsecConn = flag.Bool("InsecConn", false, "TLS insecure connectivity")
 	t.SkipVerify = flag.Bool("SkipVerify", false, "skip TLS certificate chain verification")
@@ -60,31 +131,39 @@ func main() {
 	t.Cert = flag.String("cert", "", "Client certificate file in PEM format")
 	t.Key = flag.String("key", "", "Client private key file")
 	flag.Parse()
-
-	// init operational vars
-	var (
-		cfgFileName             string             // name of config file
-		cfg                     string             // dev configuration
-		cfgCClab                string             // dev config cleaned up from clab artifacts
-		o                       *response.Response // SSH response
-		okHostname, okSwVersion bool               // vars to verify key presence in order to build file name components
-		hostname, swVersion     string
-		cmdShVer                string = "show version"
-		cmdInfo                 string = "info | as text"
-		cmdInfoPipe             string = fmt.Sprintf("info > /tmp/%s", *f.rFile)
-		cmdInfoPipeJRpc         string = fmt.Sprintf("info | as text > /tmp/%s", *f.rFile)
-		cmdRmFilejRpc           string = fmt.Sprintf("file rm /tmp/%s", *f.rFile)
-	)
+	t.Retry = lib.NewRetryPolicy(*f.retries, *f.retryBackoff)
 
 	// Checking mandatory params
+	multi := *f.inventory != "" || strings.ContainsAny(*t.Hostname, ",{")
 	switch {
-	case len(*t.Hostname) == 0:
+	case len(*t.Hostname) == 0 && *f.inventory == "":
 		log.WithFields(log.Fields{
 			"exec": "checking flags and input params",
 		}).Fatalln("hostname is mandatory, but missed")
+	case multi && (*f.push != "" || *f.pull != "" || *f.restore != "" || *f.watch || *f.uploadTo != ""):
+		log.WithFields(log.Fields{
+			"exec": "checking flags and input params",
+		}).Fatalln("push, pull, restore, watch and upload support single target only")
+	case *f.snapshot != "" && *f.inventory == "":
+		log.WithFields(log.Fields{
+			"exec": "checking flags and input params",
+		}).Fatalln("snapshot requires clab topology file as inventory")
 	default:
 	}
 
+	// Parsing file name template
+	tmpl, err := template.New("fileName").Parse(*f.fileName)
+	if err == nil {
+		// Unknown fields are reported on execution only.
+		_, err = renderFileName(tmpl, &fileNameData{Hostname: "srl"}, "cfg")
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"exec": "checking flags and input params",
+		}).Fatalf("incorrect file name template: %s", err)
+	}
+	f.nameTmpl = tmpl
+
 	// setup logging
 	log.SetReportCaller(true)
 	//// level
@@ -112,271 +191,957 @@ func main() {
 		log.SetOutput(os.Stderr)
 	}
 
-	if *f.jsonRPC {
-		// Connecting via JSON-RPC
+	if *f.push != "" {
+		// Uploading file via gNOI, nothing to extract.
 		contextLogger := log.WithFields(log.Fields{
-			"topic": "JSON RPC",
+			"topic": "gNOI push",
 		})
-		contextLogger.Debug("Connecting via JSON-RPC...")
-		var resp *jrpc.JSONRpcResponse
-		var outShVer []map[string]showVersion
-		// , exec sh ver
-		resp, err := jrpc.ExecCli(t, &cmdShVer, jrpc.OutFormJSON)
+		contextLogger.Debugf("Uploading %s into %s", *f.push, *f.pushDir)
+		err := pushFile(t, *f.push, *f.pushDir, *f.pushPerm)
 		if err != nil {
-			contextLogger.Fatalf("error while exec cli command: %s", err)
+			contextLogger.Fatalf("can't push file (gNOI): %s", err)
 		}
+		return
+	}
 
-		err = json.Unmarshal(lib.JSONRawToByte(resp.Result), &outShVer)
-		contextLogger.Infof("resp.Result: %v", string(lib.JSONRawToByte(resp.Result)))
+	if *f.pull != "" {
+		// Downloading remote directory via gNOI, nothing to extract.
+		contextLogger := log.WithFields(log.Fields{
+			"topic": "gNOI pull",
+		})
+		contextLogger.Debugf("Downloading files of %s", *f.pull)
+		err := pullDir(t, *f.pull, filepath.Base(*f.pull))
 		if err != nil {
-			contextLogger.Fatalf("[]byte marshalling error: %s", err)
+			contextLogger.Fatalf("can't pull directory (gNOI): %s", err)
 		}
+		return
+	}
 
-		hostname, okHostname = outShVer[0]["basic system info"]["Hostname"]
-		swVersion, okSwVersion = outShVer[0]["basic system info"]["Software Version"]
-		if !(okHostname && okSwVersion) {
-			contextLogger.Fatalf("failed to parse show version output; error: %+v\n", err)
+	if *f.restore != "" {
+		// Replacing config via gNMI, nothing to extract.
+		contextLogger := log.WithFields(log.Fields{
+			"topic": "gNMI restore",
+		})
+		var paths []string
+		if *f.restorePaths != "" {
+			paths = strings.Split(*f.restorePaths, ",")
 		}
+		contextLogger.Debugf("Restoring %v from %s", paths, *f.restore)
+		err := restoreConfig(t, *f.restore, paths)
+		if err != nil {
+			contextLogger.Fatalf("can't restore config (gNMI): %s", err)
+		}
+		return
+	}
 
-		// , exec info / create file
-		if *f.gNOIdld {
-			// Creating file to download with gNOI
-			contextLogger.Debug("Creating file to download with gNOI...")
-			resp, err = jrpc.ExecCli(t, &cmdInfoPipeJRpc, jrpc.OutFormText)
-			if err != nil {
-				contextLogger.Fatalf("error while exec cli command: %s", err)
-			}
-			// check for the errors
-			contextLogger.Infof("checking for command execution errors; resp.Result: %s", string(lib.JSONRawToByte(resp.Result)))
-			if strings.Compare(string(lib.JSONRawToByte(resp.Result)), "[{}]") != 0 {
-				contextLogger.Fatalf("expect no outputs, but got: %s", lib.JSONRawToByte(resp.Result))
-			}
-			*f.rFile = fmt.Sprintf("/tmp/%s", *f.rFile)
-			// defer cleanup, bcz specific permissions jsonrpc:tls
-			defer jrpc.ExecCli(t, &cmdRmFilejRpc, jrpc.OutFormText)
-
-		} else {
-			var outJSONText []jsonOutText
-			var ok bool
-			// exec info and scrape it
-			contextLogger.Debugf("exec %s and scrape it", cmdInfo)
-			resp, err = jrpc.ExecCli(t, &cmdInfo, jrpc.OutFormText)
-			if err != nil {
-				contextLogger.Debugf("JSON RPC response: ", resp)
-				contextLogger.Fatalf("error while exec cli command: %s", err)
-			}
-			err = json.Unmarshal(lib.JSONRawToByte(resp.Result), &outJSONText)
-			if err != nil {
-				contextLogger.Fatalf("[]byte marshalling error: %s", err)
-			}
-			cfg, ok = outJSONText[0]["text"] // populating cfg info
-			contextLogger.Infof("populating cfg info outJSONText[0]['text'] trunk up to 128: %s", outJSONText[0]["text"][:128])
-			if !ok {
-				log.WithFields(log.Fields{
-					"exec": "JSON RPC",
-				}).Fatalf("expected text element, but was not found")
-			}
+	if *f.gNMI && (*f.jsonRPC || *f.gNOIdld) {
+		log.WithFields(log.Fields{
+			"exec": "checking flags and input params",
+		}).Fatalln("gNMI can't be combined with JSON RPC or gNOI download")
+	}
+	if *f.transports != "" {
+		if *f.uploadTo != "" {
+			log.WithFields(log.Fields{
+				"exec": "checking flags and input params",
+			}).Fatalln("transports can't be combined with upload, it requires gNOI download")
+		}
+		srcs := configSources(f)
+		err := srcs.Check(transports(f)...)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"exec": "checking flags and input params",
+			}).Fatalf("%s, known ones: %s", err, strings.Join(srcs.Names(), ", "))
 		}
+	}
 
-	} else {
-		// Connecting via SSH and issue necessary commands
+	if multi {
+		// Backing up all targets concurrently.
+		err := backupAll(t, f)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"topic": "inventory",
+			}).Fatal(err)
+		}
+		return
+	}
+
+	if *f.watch {
+		// Watching commits via gNMI, config is extracted by selected transport on every commit.
 		contextLogger := log.WithFields(log.Fields{
-			"topic": "SSH",
+			"topic": "gNMI watch",
 		})
-		contextLogger.Debug("Connecting via SSH and issue necessary commands...")
-		var opts []util.Option
-		if *t.NoStrictKey {
-			opts = append(opts, options.WithAuthNoStrictKey())
-		}
-		opts = append(opts,
-			options.WithAuthPassword(*t.Password),
-			options.WithAuthUsername(*t.Username),
-		)
-
-		if *f.logSSH {
-			opts = append(opts, options.WithDefaultLogger())
-		}
-
-		// Creating platform object and driver
-		contextLogger.Debug("Creating platform object and driver")
-		p, err := platform.NewPlatform(
-			platform.NokiaSrl,
-			*t.Hostname,
-			opts...)
-		if err != nil {
-			contextLogger.Fatalf("failed to create platform; error: %+v\n", err)
-			return
+		if *f.uploadTo != "" {
+			contextLogger.Fatal("watch can't be combined with upload")
 		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		err := watchConfig(ctx, t, f)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			contextLogger.Fatalf("watch stopped: %s", err)
+		}
+		return
+	}
 
-		d, err := p.GetNetworkDriver()
+	// Extracting config over transport selected
+	contextLogger = log.WithFields(log.Fields{
+		"topic": "extract",
+	})
+	if *f.gNOIdld && *f.uploadTo != "" {
+		// The target uploads the file to the collector itself.
+		err := uploadConfig(t, f)
 		if err != nil {
-			contextLogger.Fatalf("failed to open driver; error: %+v\n", err)
-			return
+			contextLogger.Fatalf("can't upload config (gNOI): %s", err)
 		}
+		return
+	}
+	e, err := extractConfig(context.Background(), t, f, transports(f))
+	if err != nil {
+		contextLogger.Fatal(err)
+	}
 
-		// Open SSH connection toward specified target
-		contextLogger.Debug("Open SSH connection toward specified target")
-		err = d.Open()
+	// Saving target configuration
+	cfgFileName, err := saveConfig(f, e, *f.outDir)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"topic": "saving config",
+		}).Errorf("saving target cfg: %v", err)
+		return
+	}
+	fmt.Printf("config saved into %s via %s\n", cfgFileName, e.Transport)
+}
+
+// Function saves extracted config into the directory under the name rendered by file name template,
+// and removes old configs of the target according to retention flags. Returns name of the saved file.
+func saveConfig(f *cliOpt, e *lib.Extraction, dir string) (string, error) {
+	name, err := fileName(f, e, time.Now())
+	if err != nil {
+		return "", err
+	}
+	cfgFileName := filepath.Join(dir, name)
+	log.Debugf("saving config to the file %s", cfgFileName)
+	err = saveTargetConfig(cfgFileName, e.Config)
+	if err != nil {
+		return "", err
+	}
+	err = removeOldConfigs(f, e, dir, cfgFileName)
+	if err != nil {
+		log.Warnf("can't apply retention policy: %s", err)
+	}
+	return cfgFileName, nil
+}
+
+// Function saves config into the file. Config is written into temporary file, which replaces the existing one
+// once the config is written completely, so the previous backup isn't lost if saving fails.
+func saveTargetConfig(cfgFileName string, cfg string) error {
+	dir := filepath.Dir(cfgFileName)
+	err := os.MkdirAll(dir, 0750)
+	if err != nil {
+		return fmt.Errorf("can't create config directory: %s", err)
+	}
+	fh, err := os.CreateTemp(dir, "."+filepath.Base(cfgFileName)+".*")
+	if err != nil {
+		return fmt.Errorf("can't save config file: %s", err)
+	}
+	// Temporary file is left only if saving fails.
+	defer os.Remove(fh.Name())
+	defer fh.Close()
+	_, err = fh.WriteString(cfg)
+	if err != nil {
+		return fmt.Errorf("can't write config file: %s", err)
+	}
+	err = fh.Sync()
+	if err != nil {
+		return fmt.Errorf("can't flush config file to disk: %s", err)
+	}
+	err = fh.Chmod(0740)
+	if err != nil {
+		return fmt.Errorf("can't set config file permissions: %s", err)
+	}
+	err = os.Rename(fh.Name(), cfgFileName)
+	if err != nil {
+		return fmt.Errorf("can't save config file: %s", err)
+	}
+	return nil
+}
+
+// Function removes configs of the target from the directory, except the latest one, if they're out of
+// -keep number or older than -keepFor. Configs of the target are the files matching file name template
+// rendered with the hostname of the target and wildcards for the rest of fields.
+func removeOldConfigs(f *cliOpt, e *lib.Extraction, dir string, latest string) error {
+	if *f.keep <= 0 && *f.keepFor <= 0 {
+		return nil
+	}
+	pattern, err := renderFileName(f.nameTmpl, &fileNameData{
+		Hostname:        escapeGlob(e.Version.Hostname),
+		SoftwareVersion: "*",
+		ChassisType:     "*",
+		Transport:       "*",
+		Timestamp:       "*",
+	}, fileExt(e))
+	if err != nil {
+		return err
+	}
+	names, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return fmt.Errorf("can't list configs: %s", err)
+	}
+
+	type backup struct {
+		name  string
+		mTime time.Time
+	}
+	var bs []backup
+	for _, n := range names {
+		if n == latest {
+			continue
+		}
+		fi, err := os.Stat(n)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		bs = append(bs, backup{name: n, mTime: fi.ModTime()})
+	}
+	// Newest first, the latest config is counted as kept.
+	sort.Slice(bs, func(i, j int) bool {
+		return bs[i].mTime.After(bs[j].mTime)
+	})
+	for i, b := range bs {
+		if (*f.keep > 0 && i+1 >= *f.keep) || (*f.keepFor > 0 && time.Since(b.mTime) > *f.keepFor) {
+			err = os.Remove(b.name)
+			if err != nil {
+				return fmt.Errorf("can't remove old config: %s", err)
+			}
+			log.Infof("old config %s removed", b.name)
+		}
+	}
+	return nil
+}
+
+// Returns s with glob metacharacters escaped, so it's matched literally by filepath.Glob.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`\*?[]`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Function returns config transports selected by the flags: the list of ones to fall back through,
+// or single one of gNMI, gNOI download and CLI.
+func transports(f *cliOpt) []string {
+	switch {
+	case *f.transports != "":
+		var names []string
+		for _, n := range strings.Split(*f.transports, ",") {
+			if n = strings.TrimSpace(n); n != "" {
+				names = append(names, n)
+			}
+		}
+		return names
+	case *f.gNMI:
+		return []string{gnmi.SourceName}
+	case *f.gNOIdld:
+		return []string{file.SourceName}
+	default:
+		return []string{cliTransport(f)}
+	}
+}
+
+// Function returns CLI transport selected by the flags: JSON RPC or SSH.
+func cliTransport(f *cliOpt) string {
+	if *f.jsonRPC {
+		return jrpc.SourceName
+	}
+	return ssh.SourceName
+}
+
+// Function returns config sources configured by the flags: SSH logging and CLI transport gNOI source saves config via.
+// gNOI source falls back from JSON RPC to SSH, unless gNOI download with selected CLI transport is requested.
+func configSources(f *cliOpt) lib.Sources {
+	var sshOpts []util.Option
+	if *f.logSSH {
+		sshOpts = append(sshOpts, options.WithDefaultLogger())
+	}
+	sshSrc := ssh.NewConfigSourceFunc(sshOpts...)
+	clis := []lib.ConfigSourceFunc{jrpc.NewConfigSource, sshSrc}
+	switch {
+	case *f.gNOIdld && *f.jsonRPC:
+		clis = clis[:1]
+	case *f.gNOIdld:
+		clis = clis[1:]
+	}
+	return lib.Sources{
+		ssh.SourceName:  sshSrc,
+		file.SourceName: file.NewConfigSourceFunc(clis...),
+	}
+}
+
+// Extracts config over the transports, cleans up clab artifacts and prints info tree, if requested by the flags.
+// Interfaces enabled by clab for the endpoints of the node are cleaned up as well, if they're known.
+// Extraction is aborted, once ctx is done.
+func extractConfig(ctx context.Context, t *lib.SRLTarget, f *cliOpt, transports []string, endpoints ...string) (*lib.Extraction, error) {
+	e, err := configSources(f).ExtractConfig(ctx, t, transports...)
+	if err != nil {
+		return nil, err
+	}
+	for _, fe := range e.Failed {
+		log.Warnf("falling back from %s", fe)
+	}
+	log.Infof("config of %s (%s, %s) extracted via %s: %d bytes", e.Version.Hostname, e.Version.ChassisType, e.Version.SoftwareVersion, e.Transport, len(e.Config))
+
+	// Clean-up clab configuration artifacts
+	if *f.cleanUpClabConfig {
+		log.WithFields(log.Fields{
+			"topic": "cleanUpClabConfig",
+		}).Debug("Clean-up clab configuration artifacts")
+		err = e.CleanUpClab(endpoints...)
 		if err != nil {
 			log.WithFields(log.Fields{
-				"topic": "SSH",
-			}).Fatalf("failed to open SSH connection toward %+v; error: %+v\n", d.Transport.GetHost(), err)
-			return
+				"exec": "clean up clab cfg elem",
+			}).Error(err)
 		}
-		defer d.Close()
+	}
 
-		// Classic HA-HA
-		contextLogger.Debug("Exec show version")
-		o, err = d.SendCommand("show version")
+	// Printing info object Tree
+	if *f.printTree {
+		if e.Format != lib.ConfigFormatInfo {
+			log.Warnf("info tree can't be printed for config retrieved via %s", e.Transport)
+			return e, nil
+		}
+		root, err := lib.NewInfoObject(e.Config)
 		if err != nil {
-			contextLogger.Fatalf("failed to send command; error: %+v\n ", err)
-			return
+			log.WithFields(log.Fields{
+				"exec": "parsing cfg -> info tree",
+			}).Error(err)
+			return e, nil
 		}
+		lib.PrintInfObjTree(root)
+	}
+	return e, nil
+}
 
-		shVerMap, err := o.TextFsmParse(`../tfsm/srl.show_version.template`)
-		if err != nil {
-			contextLogger.Fatalf("failed to parse show version output; error: %+v\n", err)
+// Returns file name of the config rendered by file name template of the flags with extracted at ts.
+func fileName(f *cliOpt, e *lib.Extraction, ts time.Time) (string, error) {
+	if e.Version.Hostname == "" || e.Version.SoftwareVersion == "" {
+		return "", fmt.Errorf("hostname or software version of the target is missed, can't build file name")
+	}
+	return renderFileName(f.nameTmpl, &fileNameData{
+		Hostname:        e.Version.Hostname,
+		SoftwareVersion: e.Version.SoftwareVersion,
+		ChassisType:     e.Version.ChassisType,
+		Transport:       e.Transport,
+		Timestamp:       ts.Format("20060102T150405"),
+	}, fileExt(e))
+}
+
+// Function renders file name template and appends the extension.
+func renderFileName(tmpl *template.Template, d *fileNameData, ext string) (string, error) {
+	var b strings.Builder
+	err := tmpl.Execute(&b, d)
+	if err != nil {
+		return "", fmt.Errorf("can't render file name: %s", err)
+	}
+	name := strings.TrimSpace(b.String())
+	if name == "" {
+		return "", fmt.Errorf("file name template renders empty name")
+	}
+	return strings.Join([]string{name, ext}, "."), nil
+}
+
+// Returns extension of the config file: cfg for info config and json for JSON one.
+func fileExt(e *lib.Extraction) string {
+	if e.Format == lib.ConfigFormatJSON {
+		return "json"
+	}
+	return "cfg"
+}
+
+// Function saves info config into the file on the target via CLI transport and instructs the target to upload it.
+func uploadConfig(t *lib.SRLTarget, f *cliOpt) error {
+	src, err := configSources(f).Open(context.Background(), t, cliTransport(f))
+	if err != nil {
+		return err
+	}
+	defer src.Cleanup()
+	cli, ok := src.(lib.CommandRunner)
+	if !ok {
+		return fmt.Errorf("%s transport can't run CLI commands", cliTransport(f))
+	}
+
+	v, err := src.ShowVersion()
+	if err != nil {
+		return err
+	}
+	rFile := path.Join("/tmp", *f.rFile)
+	out, err := cli.RunCLI(fmt.Sprintf("info | as text > %s", rFile))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// File created via JSON RPC can't be removed by gNOI due to permissions.
+		if err := file.RemoveFile(t, &rFile); err != nil {
+			cli.RunCLI(fmt.Sprintf("file rm %s", rFile))
 		}
-		hostname, okHostname = shVerMap[0]["Hostname"].(string)
-		swVersion, okSwVersion = shVerMap[0]["SoftwareVersion"].(string)
-		contextLogger.Infof("shVerMap[0]['Hostname'], shVerMap[0]['SoftwareVersion']: %+v, %+v\n", shVerMap[0]["Hostname"].(string), shVerMap[0]["SoftwareVersion"].(string))
-		if !(okHostname && okSwVersion) {
-			contextLogger.Fatalf("failed to passe show version output; error: %+v\n", err)
+	}()
+	if len(out) != 0 {
+		if strings.Contains(out, "Permission denied") {
+			return fmt.Errorf("file permission issue, file could be created via jsonrpc previously: %s", out)
 		}
+		return fmt.Errorf("expect no output, but got: %s", out)
+	}
 
-		// , exec info
-		contextLogger.Debug("Exec info")
-		if *f.gNOIdld {
-			// Creating file to download via gNOI
-			contextLogger.Debug("Creating file to download via gNOI")
-			o, err := d.SendCommand(cmdInfoPipe)
-			if err != nil {
-				log.WithFields(log.Fields{
-					"exec": "SSH",
-				}).Fatalf("failed to send command; error: %+v\n ", err)
-				return
-			}
+	stats, err := file.Stat(t, rFile)
+	if err != nil {
+		return fmt.Errorf("can't stat remote file %s: %s", rFile, err)
+	}
+	if len(stats) != 1 || stats[0].Path != rFile {
+		return fmt.Errorf("remote file %s doesn't exist or is a directory", rFile)
+	}
+	log.Debugf("remote file %s: %d bytes, modified %s", stats[0].Path, stats[0].Size, stats[0].MTime)
 
-			// check for the errors
-			contextLogger.Debugf("checking for command execution errors; o.Result: %v", o.Result)
-			if len(o.Result) != 0 {
-				if strings.Contains(o.Result, "Permission denied") {
-					contextLogger.Fatalf("file permission issue, file could be created via jsonrpc previously: %s\n", o.Result)
-				}
-				contextLogger.Fatalf("expect no output, but got: %s\n", o.Result)
-				return
-			}
-			*f.rFile = fmt.Sprintf("/tmp/%s", *f.rFile)
+	cfgFileName, err := fileName(f, &lib.Extraction{Version: v, Format: lib.ConfigFormatInfo, Transport: file.SourceName}, time.Now())
+	if err != nil {
+		return err
+	}
+	rURL, err := uploadURL(*f.uploadTo, cfgFileName)
+	if err != nil {
+		return fmt.Errorf("incorrect upload URL: %s", err)
+	}
+	// Config isn't streamed through the host, so hash reported by the target is left to the collector to verify.
+	h, err := file.TransferToRemote(t, rFile, rURL, nil, *f.transferTimeout)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("config transferred into %s, %s: %x\n", cfgFileName, h.GetMethod(), h.GetHash())
+	return nil
+}
+
+func restoreConfig(t *lib.SRLTarget, cfgFile string, paths []string) error {
+	b, err := os.ReadFile(cfgFile)
+	if err != nil {
+		return fmt.Errorf("can't read config file: %s", err)
+	}
 
-		} else {
-			// scraping info
-			contextLogger.Debug("scraping info")
-			o, err = d.SendCommand("info")
+	ch := new(gnmi.Changes)
+	if filepath.Ext(cfgFile) == ".json" {
+		// JSON config saved via gNMI is replaced as is.
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		var cfg map[string]any
+		err = d.Decode(&cfg)
+		if err != nil {
+			return fmt.Errorf("can't decode config file: %s", err)
+		}
+		ch.Replaces = make(map[string]any)
+		if len(paths) == 0 {
+			// Config saved w/o clab management settings would lock the target out of gNMI access.
+			if _, err := gnmi.Subtree(cfg, "/system/gnmi-server"); err != nil {
+				return fmt.Errorf("config has no system gnmi-server, replacing the whole config would disable gNMI; restore paths instead")
+			}
+			ch.Replaces["/"] = cfg
+		}
+		for _, p := range paths {
+			ch.Replaces[p], err = gnmi.Subtree(cfg, p)
 			if err != nil {
-				contextLogger.Fatalf("failed to send command; error: %+v\n ", err)
-				return
+				return err
 			}
-			contextLogger.Debugf("o.Result trunk up to 128: %v", o.Result[:128])
-			cfg = o.Result // populating info
 		}
+		return gnmi.Set(t, ch)
 	}
 
-	// Crafting the name of the local config file.
-	cfgFileName = strings.Join([]string{hostname, swVersion}, "_")
-	cfgFileName = strings.Join([]string{cfgFileName, "cfg"}, ".")
-	log.WithFields(log.Fields{
-		"topic": "cfgFileName",
-	}).Debugf("Crafting the name of the local config file: %s", cfgFileName)
-
-	if *f.gNOIdld {
-		// Downloading file using gNOI, if option is selected
-		contextLogger := log.WithFields(log.Fields{
-			"topic": "SSH",
-		})
-		contextLogger.Debug("Downloading file using gNOI, if option is selected")
-		err := file.GetFile(t, f.rFile, &cfgFileName)
+	// Info config is applied via CLI.
+	if len(paths) == 0 {
+		ch.CLI, err = gnmi.InfoReplace(string(b))
 		if err != nil {
-			contextLogger.Fatalf("can't get file (gNOI): %s", err)
+			return err
 		}
-		// clean up the target in case created not via JSON RPC
-		if !*f.jsonRPC {
-			contextLogger.Debug("clean up the target in case created not via JSON RPC")
-			defer file.RemoveFile(t, f.rFile)
+	}
+	for _, p := range paths {
+		keys, err := gnmi.InfoKeys(p)
+		if err != nil {
+			return err
+		}
+		cmds, err := gnmi.InfoReplace(string(b), keys...)
+		if err != nil {
+			return err
 		}
+		ch.CLI += cmds
+	}
+	return gnmi.Set(t, ch)
+}
 
-		if !(*f.cleanUpClabConfig || *f.printTree) {
-			contextLogger.Debug("No clean-up or printTree requested, exiting...")
-			return
+func pushFile(t *lib.SRLTarget, lFile string, rDir string, perm string) error {
+	p, err := strconv.ParseUint(perm, 8, 32)
+	if err != nil {
+		return fmt.Errorf("incorrect permissions %s: %s", perm, err)
+	}
+	rFile := path.Join(rDir, filepath.Base(lFile))
+	return file.PutFile(t, &lFile, &rFile, os.FileMode(p))
+}
+
+func pullDir(t *lib.SRLTarget, rDir string, lDir string) error {
+	// All files are downloaded over the same connection.
+	s, err := session.New(t)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	c := file.NewClient(s)
+
+	files, err := c.List(rDir)
+	if err != nil {
+		return err
+	}
+	for _, st := range files {
+		rel, err := filepath.Rel(rDir, st.Path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("unexpected remote path %s in %s", st.Path, rDir)
+		}
+		lFile := filepath.Join(lDir, rel)
+		err = os.MkdirAll(filepath.Dir(lFile), 0750)
+		if err != nil {
+			return fmt.Errorf("can't create local directory: %s", err)
 		}
-		fh, err := os.OpenFile(cfgFileName, os.O_RDWR, 0740)
+		log.Debugf("Downloading %s (%d bytes) into %s", st.Path, st.Size, lFile)
+		w, err := file.CreateWriter(lFile)
 		if err != nil {
-			contextLogger.Fatalf("can't open downloaded configuration file: %v", err)
+			return err
 		}
-		c, err := ioutil.ReadAll(fh)
-		fh.Close()
+		_, err = c.GetTo(st.Path, w, nil)
 		if err != nil {
-			contextLogger.Fatalf("can't read saved configuration file: %v", err)
+			w.Abort()
+			return err
+		}
+		err = w.Close()
+		if err != nil {
+			os.Remove(lFile)
+			return err
 		}
-		cfg = string(c)
 	}
+	return nil
+}
 
-	// Creating info tree
-	var root *lib.InfoObject
-	var err error
-	if *f.cleanUpClabConfig || *f.printTree {
-		// Creating info tree
-		log.WithFields(log.Fields{
-			"topic": "InfoObject",
-		}).Debug("Creating info tree")
-		root, err = lib.NewInfoObject(cfg)
+func uploadURL(base string, name string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, name)
+	return u.String(), nil
+}
+
+// Commit history of the target, every config commit creates an entry keyed by id.
+const pathCommit = "/system/configuration/commit"
+
+// Audit record of the commit appended into the audit log as JSON line.
+type auditRecord struct {
+	Time      time.Time `json:"time"`
+	Target    string    `json:"target"`
+	Commit    string    `json:"commit"`
+	Username  string    `json:"username,omitempty"`
+	Session   string    `json:"session,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	Started   string    `json:"time-started,omitempty"`
+	Backup    string    `json:"backup,omitempty"`
+	Previous  string    `json:"previous-backup,omitempty"` // backup the commit changes are made against
+	Transport string    `json:"transport,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Watcher of the commits, leaves of every commit are collected till it's completed.
+type commitWatcher struct {
+	ctx     context.Context // extractions are aborted, once the watch is over
+	t       *lib.SRLTarget
+	f       *cliOpt
+	audit   *os.File
+	commits map[string]map[string]string // commit id to leaf values
+	done    map[string]bool              // commits already backed up or seen before watch started
+	last    string                       // the latest backup saved by the watch
+	synced  bool                         // initial state of the current stream is received
+	started bool                         // initial state of the first stream is received
+}
+
+// Subscribes to config commits and saves timestamped backup of the config extracted by selected transport
+// on every completed commit. Stream is re-established with backoff on errors, blocks till ctx is cancelled.
+func watchConfig(ctx context.Context, t *lib.SRLTarget, f *cliOpt) error {
+	err := os.MkdirAll(*f.outDir, 0750)
+	if err != nil {
+		return fmt.Errorf("can't create backup directory: %s", err)
+	}
+	auditLog := *f.auditLog
+	if auditLog == "" {
+		auditLog = filepath.Join(*f.outDir, "audit.log")
+	}
+	fh, err := os.OpenFile(auditLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("can't open audit log: %s", err)
+	}
+	defer fh.Close()
+
+	w := &commitWatcher{ctx: ctx, t: t, f: f, audit: fh, commits: make(map[string]map[string]string), done: make(map[string]bool)}
+	s, err := session.NewWithPort(t, *t.PortgNMI)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	subs := []*gnmi.Subscription{{Path: pathCommit, Mode: gnmi.ModeOnChange}}
+	return gnmi.NewClient(s).SubscribeRetry(ctx, subs, w.notify, func(err error, d time.Duration) {
+		// Initial state of the next stream is awaited.
+		w.synced = false
+		log.Warnf("commit stream is broken: %s; reconnecting in %s", err, d)
+	})
+}
+
+// Function collects commit leaves, commits completed before the first sync are considered as backed up,
+// ones completed while stream was broken are backed up at once on sync.
+func (w *commitWatcher) notify(n *gnmi.Notification) error {
+	if n.Sync {
+		w.synced = true
+		if !w.started {
+			w.started = true
+			for id := range w.commits {
+				w.done[id] = true
+			}
+			log.Infof("watching commits of %s, %d known", *w.t.Hostname, len(w.done))
+			return nil
+		}
+		return w.backup(w.completed())
+	}
+
+	for _, u := range n.Updates {
+		id, leaves, err := commitLeaves(u)
 		if err != nil {
-			log.WithFields(log.Fields{
-				"exec": "parsing cfg -> info tree",
-			}).Error(err)
+			log.Warnf("skipping update of %s: %s", u.Path, err)
+			continue
+		}
+		if w.commits[id] == nil {
+			w.commits[id] = make(map[string]string)
+		}
+		for k, v := range leaves {
+			w.commits[id][k] = v
+		}
+	}
+	for _, p := range n.Deletes {
+		// History is trimmed by the target.
+		id, _, err := commitLeaves(&gnmi.Update{Path: p})
+		if err == nil {
+			delete(w.commits, id)
 		}
 	}
+	if !w.synced {
+		return nil
+	}
+	return w.backup(w.completed())
+}
 
-	// Clean-up clab configuration artifacts
-	if *f.cleanUpClabConfig {
-		log.WithFields(log.Fields{
-			"topic": "cleanUpClabConfig",
-		}).Debug("Clean-up clab configuration artifacts")
-		cfgCClab, err = lib.CleanUpClabInfoObjects(root, cfg)
+// Function returns ids of completed commits not backed up yet, sorted numerically.
+func (w *commitWatcher) completed() []string {
+	var ids []string
+	for id, leaves := range w.commits {
+		if _, ok := leaves["time-completed"]; ok && !w.done[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if len(ids[i]) != len(ids[j]) {
+			return len(ids[i]) < len(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// Function saves single backup covering the commits and records them into the audit log.
+// Extraction errors are recorded and don't stop the watch, audit log errors do. Commits failed to be backed up
+// are left pending, so they're retried on the next notification or sync.
+func (w *commitWatcher) backup(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var name, transport, errMsg string
+	e, err := extractConfig(w.ctx, w.t, w.f, transports(w.f))
+	if err == nil {
+		transport = e.Transport
+		name, err = saveConfig(w.f, e, *w.f.outDir)
+	}
+	if err != nil {
+		log.Errorf("can't backup config after commit %s: %s", ids[len(ids)-1], err)
+		name, errMsg = "", err.Error()
+	} else {
+		log.Infof("config after commit %s saved into %s", ids[len(ids)-1], name)
+	}
+
+	for _, id := range ids {
+		c := w.commits[id]
+		r := &auditRecord{
+			Time:      time.Now(),
+			Target:    *w.t.Hostname,
+			Commit:    id,
+			Username:  c["username"],
+			Session:   c["session-id"],
+			Comment:   c["comment"],
+			Started:   c["time-started"],
+			Backup:    name,
+			Previous:  w.last,
+			Transport: transport,
+			Error:     errMsg,
+		}
+		b, err := json.Marshal(r)
 		if err != nil {
-			log.WithFields(log.Fields{
-				"exec": "clean up clab cfg elem",
-			}).Error(err)
+			return fmt.Errorf("can't marshal audit record: %s", err)
+		}
+		_, err = w.audit.Write(append(b, '\n'))
+		if err != nil {
+			return fmt.Errorf("can't write audit log: %s", err)
 		}
-		cfg = cfgCClab
+		if errMsg == "" {
+			w.done[id] = true
+		}
+	}
+	if errMsg == "" {
+		w.last = name
 	}
+	return nil
+}
 
-	// Printing info object Tree
-	if *f.printTree {
-		lib.PrintInfObjTree(root)
+// Function returns commit id and leaf values of the update, value could be either single leaf or commit object.
+func commitLeaves(u *gnmi.Update) (string, map[string]string, error) {
+	gp, err := gnmi.ParsePath(u.Path)
+	if err != nil {
+		return "", nil, err
+	}
+	var (
+		id   string
+		rest []string
+	)
+	for i, e := range gp.GetElem() {
+		if e.GetName() == "commit" && e.GetKey()["id"] != "" {
+			id = e.GetKey()["id"]
+			for _, re := range gp.GetElem()[i+1:] {
+				rest = append(rest, re.GetName())
+			}
+			break
+		}
+	}
+	if id == "" {
+		return "", nil, fmt.Errorf("no commit id in the path")
 	}
 
-	// Saving target configuration
-	log.WithFields(log.Fields{
-		"topic": "saveTargetConfig",
-	}).Debug("saving config to the file")
-	err = saveTargetConfig(cfgFileName, cfg)
+	leaves := make(map[string]string)
+	switch v := u.Value.(type) {
+	case nil:
+	case map[string]any:
+		for k, cv := range v {
+			switch cv.(type) {
+			case map[string]any, []any:
+				continue
+			}
+			leaves[k[strings.LastIndex(k, ":")+1:]] = fmt.Sprint(cv)
+		}
+	default:
+		if len(rest) == 0 {
+			return "", nil, fmt.Errorf("unexpected value %v", v)
+		}
+		leaves[rest[len(rest)-1]] = fmt.Sprint(v)
+	}
+	return id, leaves, nil
+}
+
+// Result of the target backup.
+type backupResult struct {
+	target    string
+	file      string
+	transport string
+	took      time.Duration
+	err       error
+}
+
+// Function backs up all targets of the inventory or target patterns concurrently and prints the summary.
+// Returns an error, if any target isn't backed up.
+func backupAll(defaults *lib.SRLTarget, f *cliOpt) error {
+	var (
+		hosts []*inventory.Host
+		err   error
+	)
+	if *f.inventory != "" {
+		hosts, err = inventory.Load(*f.inventory)
+	} else {
+		hosts, err = inventory.Expand(*defaults.Hostname)
+	}
 	if err != nil {
-		log.WithFields(log.Fields{
-			"topic": "saving config",
-		}).Errorf("saving target cfg: %v", err)
+		return err
+	}
+	// Clab credentials of the nodes are overridden by explicitly provided ones.
+	explicit := make(map[string]bool)
+	flag.Visit(func(fl *flag.Flag) {
+		explicit[fl.Name] = true
+	})
+	for _, h := range hosts {
+		if h.Node != "" && explicit["username"] {
+			h.Username = ""
+		}
+		if h.Node != "" && explicit["password"] {
+			h.Password = ""
+		}
+		err = configSources(f).Check(h.Transports...)
+		if err != nil {
+			return fmt.Errorf("%s: %s", h.Hostname, err)
+		}
+		if *f.snapshot != "" && h.Node == "" {
+			return fmt.Errorf("snapshot requires clab topology file as inventory")
+		}
+	}
+	if *f.snapshot != "" {
+		if ext := filepath.Ext(*f.inventory); ext != ".yml" && ext != ".yaml" {
+			return fmt.Errorf("snapshot requires clab topology file as inventory, %s can't be patched", *f.inventory)
+		}
+		// Source topology isn't overwritten, so snapshot directory should differ from the topology one.
+		src, err := filepath.Abs(*f.inventory)
+		if err != nil {
+			return err
+		}
+		dst, err := filepath.Abs(filepath.Join(*f.snapshot, filepath.Base(*f.inventory)))
+		if err != nil {
+			return err
+		}
+		if src == dst {
+			return fmt.Errorf("snapshot would overwrite topology %s, choose another directory", *f.inventory)
+		}
+		// Snapshot is made of cleaned configs.
+		*f.cleanUpClabConfig = true
+		err = os.MkdirAll(filepath.Join(*f.snapshot, "configs"), 0750)
+		if err != nil {
+			return fmt.Errorf("can't create snapshot directory: %s", err)
+		}
+	}
+	workers := *f.workers
+	if workers < 1 {
+		workers = 1
 	}
+	log.Infof("backing up %d targets, %d at once", len(hosts), workers)
+
+	results := make([]*backupResult, len(hosts))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				names := hosts[i].Transports
+				if len(names) == 0 {
+					names = transports(f)
+				}
+				results[i] = backupTarget(hosts[i], defaults, f, names)
+			}
+		}()
+	}
+	for i := range hosts {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Printing the summary
+	var failed int
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tSTATUS\tTRANSPORT\tFILE\tTIME\tERROR")
+	for _, r := range results {
+		status := "ok"
+		errMsg := ""
+		if r.err != nil {
+			failed++
+			status, errMsg = "failed", r.err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.target, status, r.transport, r.file, r.took.Round(time.Millisecond), errMsg)
+	}
+	tw.Flush()
+	fmt.Printf("%d of %d targets backed up\n", len(results)-failed, len(results))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d targets failed", failed, len(results))
+	}
+	if *f.snapshot != "" {
+		return saveSnapshotTopology(*f.inventory, *f.snapshot, hosts, results)
+	}
+	return nil
 }
 
-func saveTargetConfig(cfgFileName string, cfg string) error {
-	fh, err := os.OpenFile(cfgFileName, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0740)
+// Function saves clab topology patched with startup-config of the nodes into snapshot directory.
+func saveSnapshotTopology(topology string, dir string, hosts []*inventory.Host, results []*backupResult) error {
+	configs := make(map[string]string)
+	for i, h := range hosts {
+		rel, err := filepath.Rel(dir, results[i].file)
+		if err != nil {
+			return fmt.Errorf("can't reference config of %s: %s", h.Node, err)
+		}
+		configs[h.Node] = filepath.ToSlash(rel)
+	}
+
+	// Relative paths of the topology are rewritten against the snapshot directory the copy is saved into.
+	srcDir, err := filepath.Abs(filepath.Dir(topology))
 	if err != nil {
-		return fmt.Errorf("can't save config file: %s", err)
+		return fmt.Errorf("can't resolve topology directory: %s", err)
+	}
+	dstDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("can't resolve snapshot directory: %s", err)
+	}
+
+	fh, err := os.Open(topology)
+	if err != nil {
+		return fmt.Errorf("can't open topology: %s", err)
 	}
 	defer fh.Close()
-	fh.WriteString(cfg)
-	err = fh.Sync()
+	var b bytes.Buffer
+	err = inventory.PatchClabTopology(fh, &b, configs, srcDir, dstDir)
 	if err != nil {
-		return fmt.Errorf("can't flush config file to disk: %s", err)
+		return err
+	}
+	name := filepath.Join(dir, filepath.Base(topology))
+	err = os.WriteFile(name, b.Bytes(), 0640)
+	if err != nil {
+		return fmt.Errorf("can't save topology: %s", err)
 	}
+	fmt.Printf("lab snapshot saved into %s\n", name)
 	return nil
 }
+
+// Function extracts and saves config of the host within target timeout, clab nodes are reported by node name.
+func backupTarget(h *inventory.Host, defaults *lib.SRLTarget, f *cliOpt, transports []string) *backupResult {
+	t := h.Target(defaults)
+	r := &backupResult{target: h.Hostname}
+	if h.Node != "" {
+		r.target = h.Node
+	}
+	start := time.Now()
+	defer func() {
+		r.took = time.Since(start)
+	}()
+
+	// Worker is busy till the extraction is aborted, so no more than workers targets are backed up at once.
+	ctx, cancel := context.WithTimeout(context.Background(), *f.targetTimeout)
+	defer cancel()
+	e, err := extractConfig(ctx, t, f, transports, h.Endpoints...)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("timed out after %s: %s", *f.targetTimeout, err)
+		}
+		log.WithFields(log.Fields{
+			"target": r.target,
+		}).Error(err)
+		r.err = err
+		return r
+	}
+
+	r.transport = e.Transport
+	if *f.snapshot != "" {
+		r.file = filepath.Join(*f.snapshot, filepath.FromSlash(inventory.ClabStartupConfig(h.Node, e.Format)))
+		r.err = saveTargetConfig(r.file, e.Config)
+		return r
+	}
+	r.file, r.err = saveConfig(f, e, *f.outDir)
+	return r
+}